@@ -2,18 +2,30 @@ package pulse
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/common-nighthawk/go-figure"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 )
 
 type (
 	Pulse struct {
-		config *Config
-		server *http.Server
-		Router *Router
+		config         *Config
+		server         *http.Server
+		redirectServer *http.Server
+		mu             sync.Mutex
+		routeHooks     []func(route *Route) error
+		routeHookErr   error
+		startHooks     []func() error
+		listenHooks    []func(addr string) error
+		shutdownHooks  []func() error
+		Router         *Router
 	}
 
 	Config struct {
@@ -22,6 +34,45 @@ type (
 
 		// Network is the network to use
 		Network string `json:"network"`
+
+		// ErrorHandler handles the errors returned by handlers, it defaults
+		// to DefaultErrorHandler
+		ErrorHandler ErrorHandler `json:"-"`
+
+		// ReadTimeout is the maximum duration for reading a whole request,
+		// body included. The timeouts below default to their Default value
+		// when zero and are disabled when negative.
+		ReadTimeout time.Duration `json:"read_timeout"`
+
+		// ReadHeaderTimeout is the maximum duration for reading the headers
+		// of a request
+		ReadHeaderTimeout time.Duration `json:"read_header_timeout"`
+
+		// WriteTimeout is the maximum duration before timing out the writes
+		// of a response
+		WriteTimeout time.Duration `json:"write_timeout"`
+
+		// IdleTimeout is the maximum duration to wait for the next request
+		// on a keep-alive connection
+		IdleTimeout time.Duration `json:"idle_timeout"`
+
+		// ShutdownTimeout is the maximum duration Stop waits for in-flight
+		// requests to finish before closing their connections
+		ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+
+		// GracefulShutdown makes Run and RunTLS stop the server gracefully
+		// on SIGINT or SIGTERM instead of letting the process be killed
+		// with requests in flight
+		GracefulShutdown bool `json:"graceful_shutdown"`
+
+		// MaxHeaderBytes is the maximum size of the request headers, it
+		// defaults to DefaultMaxHeaderBytes
+		MaxHeaderBytes int `json:"max_header_bytes"`
+
+		// HTTPRedirectAddress is the address on which RunTLS and
+		// RunTLSWithConfig redirect plain HTTP requests to HTTPS, no
+		// redirect listener is started when empty
+		HTTPRedirectAddress string `json:"http_redirect_address"`
 	}
 )
 
@@ -31,12 +82,29 @@ const (
 
 	// DefaultNetwork is the default network
 	DefaultNetwork = "tcp"
+
+	// DefaultReadTimeout is the default read timeout
+	DefaultReadTimeout = 30 * time.Second
+
+	// DefaultReadHeaderTimeout is the default read header timeout
+	DefaultReadHeaderTimeout = 10 * time.Second
+
+	// DefaultWriteTimeout is the default write timeout
+	DefaultWriteTimeout = 30 * time.Second
+
+	// DefaultIdleTimeout is the default idle timeout
+	DefaultIdleTimeout = 120 * time.Second
+
+	// DefaultShutdownTimeout is the default shutdown timeout
+	DefaultShutdownTimeout = 5 * time.Second
+
+	// DefaultMaxHeaderBytes is the default maximum size of the request headers
+	DefaultMaxHeaderBytes = http.DefaultMaxHeaderBytes
 )
 
 func New(config ...Config) *Pulse {
 	app := &Pulse{
 		config: &Config{},
-		server: &http.Server{},
 		Router: NewRouter(),
 	}
 
@@ -52,30 +120,121 @@ func New(config ...Config) *Pulse {
 		app.config.Network = DefaultNetwork
 	}
 
+	if app.config.ErrorHandler == nil {
+		app.config.ErrorHandler = DefaultErrorHandler
+	}
+	app.attachRouter()
+
+	setDefaultDuration(&app.config.ReadTimeout, DefaultReadTimeout)
+	setDefaultDuration(&app.config.ReadHeaderTimeout, DefaultReadHeaderTimeout)
+	setDefaultDuration(&app.config.WriteTimeout, DefaultWriteTimeout)
+	setDefaultDuration(&app.config.IdleTimeout, DefaultIdleTimeout)
+	setDefaultDuration(&app.config.ShutdownTimeout, DefaultShutdownTimeout)
+
+	if app.config.MaxHeaderBytes <= 0 {
+		app.config.MaxHeaderBytes = DefaultMaxHeaderBytes
+	}
+
+	app.server = app.newServer()
+
 	return app
 }
 
-func (p *Pulse) Run(address string) {
+// setDefaultDuration sets d to def when zero and disables it when negative.
+func setDefaultDuration(d *time.Duration, def time.Duration) {
+	if *d == 0 {
+		*d = def
+	} else if *d < 0 {
+		*d = 0
+	}
+}
+
+// newServer returns an http.Server set up with the timeouts and limits of
+// the config.
+func (p *Pulse) newServer() *http.Server {
+	return &http.Server{
+		ReadTimeout:       p.config.ReadTimeout,
+		ReadHeaderTimeout: p.config.ReadHeaderTimeout,
+		WriteTimeout:      p.config.WriteTimeout,
+		IdleTimeout:       p.config.IdleTimeout,
+		MaxHeaderBytes:    p.config.MaxHeaderBytes,
+	}
+}
+
+// Handler returns the http.Handler serving the routes of the app.
+func (p *Pulse) Handler() http.Handler {
+	p.attachRouter()
+	p.Router.errorHandler = p.config.ErrorHandler
+	return RouterHandler(p.Router)
+}
+
+// Run serves HTTP on the address until the server fails or is stopped. It
+// returns the error of the listener or the server, and nil once Stop is
+// called. When Config.GracefulShutdown is set, the server is stopped
+// gracefully on SIGINT or SIGTERM.
+func (p *Pulse) Run(address string) error {
+	ctx, stop := p.signalContext()
+	defer stop()
+
+	return p.RunWithContext(ctx, address)
+}
+
+// RunWithContext serves HTTP on the address until the context is done, then
+// stops the server gracefully like Stop does and returns its error.
+func (p *Pulse) RunWithContext(ctx context.Context, address string) error {
 	// setup handler
-	handler := RouterHandler(p.Router)
-	p.server.Handler = handler
+	p.server.Handler = p.Handler()
 
 	// setup listener
-	listener, err := net.Listen(p.config.Network, address)
+	listener, err := p.listen(address)
 	if err != nil {
-		panic(fmt.Errorf("failed to listen: %v", err))
+		return err
 	}
 
 	// print startup message
 	fmt.Println(p.startupMessage(listener.Addr().String()))
 
 	// start server
-	err = p.server.Serve(listener)
-	if err != nil {
-		fmt.Errorf("failed to start server on %s: %v", listener.Addr().String(), err)
+	return p.serve(ctx, listener, p.server.Serve)
+}
+
+// signalContext returns a context cancelled on SIGINT or SIGTERM when
+// GracefulShutdown is set, and a context that is never cancelled otherwise.
+func (p *Pulse) signalContext() (context.Context, context.CancelFunc) {
+	if p.config.GracefulShutdown {
+		return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// serve runs serve on the listener until it fails or the context is done, in
+// which case the server is stopped. Closing the server is not an error.
+func (p *Pulse) serve(ctx context.Context, listener net.Listener, serve func(net.Listener) error) error {
+	addr := listener.Addr().String()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			// ServeTLS returns without closing the listener when the
+			// certificates cannot be loaded.
+			listener.Close()
+			return fmt.Errorf("failed to start server on %s: %w", addr, err)
+		}
+		return nil
+	case <-ctx.Done():
+		err := p.Stop()
+		<-errs
+		return err
 	}
 }
 
+// Stop stops accepting connections and waits for in-flight requests to finish
+// within the shutdown timeout, after which their connections are closed. The
+// shutdown hooks are run last, even when the shutdown failed.
 func (p *Pulse) Stop() error {
 	// Check if the server is already stopped.
 	if p.server == nil {
@@ -87,17 +246,46 @@ func (p *Pulse) Stop() error {
 	p.server.SetKeepAlivesEnabled(false)
 
 	// Shutdown the server gracefully to allow existing connections to finish.
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := p.shutdownContext()
 	defer cancel()
-	err := p.server.Shutdown(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to shut down server: %v", err)
+
+	var errs []error
+	if err := p.server.Shutdown(ctx); err != nil {
+		// Close the connections still active past the deadline.
+		p.server.Close()
+		errs = append(errs, fmt.Errorf("failed to shut down server: %w", err))
+	}
+
+	p.mu.Lock()
+	redirectServer := p.redirectServer
+	p.redirectServer = nil
+	p.mu.Unlock()
+	if redirectServer != nil {
+		if err := redirectServer.Shutdown(ctx); err != nil {
+			redirectServer.Close()
+			errs = append(errs, fmt.Errorf("failed to shut down redirect server: %w", err))
+		}
+	}
+
+	for _, hook := range p.shutdownHooks {
+		if err := hook(); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	// Set the server to a new instance of http.Server to allow starting it again.
-	p.server = &http.Server{}
+	p.server = p.newServer()
 
-	return nil
+	return errors.Join(errs...)
+}
+
+// shutdownContext returns the context bounding the shutdown of the server to
+// the shutdown timeout.
+func (p *Pulse) shutdownContext() (context.Context, context.CancelFunc) {
+	if p.config.ShutdownTimeout > 0 {
+		return context.WithTimeout(context.Background(), p.config.ShutdownTimeout)
+	}
+	return context.WithCancel(context.Background())
 }
 
 func (p *Pulse) startupMessage(addr string) string {
@@ -1,8 +1,13 @@
 package pulse
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -111,3 +116,246 @@ func TestPulse_Stop(t *testing.T) {
 		t.Errorf("expected error, got nil")
 	}
 }
+
+func TestNew_ServerConfig(t *testing.T) {
+	app := New()
+	if app.server.ReadTimeout != DefaultReadTimeout ||
+		app.server.ReadHeaderTimeout != DefaultReadHeaderTimeout ||
+		app.server.WriteTimeout != DefaultWriteTimeout ||
+		app.server.IdleTimeout != DefaultIdleTimeout ||
+		app.server.MaxHeaderBytes != DefaultMaxHeaderBytes {
+		t.Errorf("unexpected default server config: %+v", app.server)
+	}
+	if app.config.ShutdownTimeout != DefaultShutdownTimeout {
+		t.Errorf("ShutdownTimeout: expected %v, actual %v", DefaultShutdownTimeout, app.config.ShutdownTimeout)
+	}
+
+	app = New(Config{
+		ReadTimeout:       time.Second,
+		ReadHeaderTimeout: 2 * time.Second,
+		WriteTimeout:      -1,
+		IdleTimeout:       3 * time.Second,
+		ShutdownTimeout:   4 * time.Second,
+		MaxHeaderBytes:    4096,
+	})
+	if app.server.ReadTimeout != time.Second ||
+		app.server.ReadHeaderTimeout != 2*time.Second ||
+		app.server.WriteTimeout != 0 ||
+		app.server.IdleTimeout != 3*time.Second ||
+		app.server.MaxHeaderBytes != 4096 {
+		t.Errorf("unexpected custom server config: %+v", app.server)
+	}
+
+	// Stop replaces the server with one using the same config.
+	if err := app.Stop(); err != nil {
+		t.Errorf("failed to stop server: %v", err)
+	}
+	if app.server.ReadTimeout != time.Second || app.server.MaxHeaderBytes != 4096 {
+		t.Errorf("expected restarted server to keep the config: %+v", app.server)
+	}
+}
+
+func TestPulse_shutdownContext(t *testing.T) {
+	app := New(Config{ShutdownTimeout: time.Minute})
+	ctx, cancel := app.shutdownContext()
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > time.Minute {
+		t.Errorf("expected a deadline within a minute, got %v", deadline)
+	}
+
+	app = New(Config{ShutdownTimeout: -1})
+	ctx, cancel = app.shutdownContext()
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Errorf("expected no deadline when the shutdown timeout is disabled")
+	}
+}
+
+func TestPulse_RunWithContext(t *testing.T) {
+	app := New()
+	started := make(chan struct{})
+	app.Router.Get("/slow", func(ctx *Context) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		ctx.String("done")
+		return nil
+	})
+
+	var hooks int32
+	app.OnShutdown(func() error {
+		atomic.AddInt32(&hooks, 1)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- app.RunWithContext(ctx, "127.0.0.1:9091")
+	}()
+	waitForServer(t, "127.0.0.1:9091")
+
+	// The in-flight request is drained once the context is cancelled.
+	responses := make(chan string, 1)
+	go func() {
+		res, err := http.Get("http://127.0.0.1:9091/slow")
+		if err != nil {
+			responses <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		responses <- string(body)
+	}()
+	<-started
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Errorf("expected RunWithContext to return nil, got %v", err)
+	}
+	if body := <-responses; body != "done" {
+		t.Errorf("expected the in-flight request to finish, got %q", body)
+	}
+	if n := atomic.LoadInt32(&hooks); n != 1 {
+		t.Errorf("expected the shutdown hook to run once, ran %d times", n)
+	}
+}
+
+func TestPulse_RunWithContextDeadline(t *testing.T) {
+	app := New(Config{ShutdownTimeout: 50 * time.Millisecond})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	app.Router.Get("/stuck", func(ctx *Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	hookErr := errors.New("flush failed")
+	app.OnShutdown(func() error {
+		return hookErr
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- app.RunWithContext(ctx, "127.0.0.1:9092")
+	}()
+	waitForServer(t, "127.0.0.1:9092")
+
+	go func() {
+		if res, err := http.Get("http://127.0.0.1:9092/stuck"); err == nil {
+			res.Body.Close()
+		}
+	}()
+	<-started
+	cancel()
+
+	err := <-done
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the shutdown deadline to be exceeded, got %v", err)
+	}
+	if !errors.Is(err, hookErr) {
+		t.Errorf("expected the shutdown hook error, got %v", err)
+	}
+}
+
+func TestPulse_RunWithContextListenError(t *testing.T) {
+	app := New()
+	if err := app.RunWithContext(context.Background(), "127.0.0.1:-1"); err == nil {
+		t.Errorf("expected an error for an invalid address")
+	}
+}
+
+func TestPulse_GracefulShutdown(t *testing.T) {
+	app := New(Config{GracefulShutdown: true})
+
+	var hooks int32
+	app.OnShutdown(func() error {
+		atomic.AddInt32(&hooks, 1)
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run("127.0.0.1:9093")
+	}()
+	waitForServer(t, "127.0.0.1:9093")
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := process.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot send interrupt: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected Run to return nil, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Run to return on interrupt")
+	}
+	if n := atomic.LoadInt32(&hooks); n != 1 {
+		t.Errorf("expected the shutdown hook to run once, ran %d times", n)
+	}
+}
+
+func TestPulse_GracefulShutdownHookError(t *testing.T) {
+	app := New(Config{GracefulShutdown: true})
+
+	hookErr := errors.New("flush failed")
+	app.OnShutdown(func() error {
+		return hookErr
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run("127.0.0.1:9100")
+	}()
+	waitForServer(t, "127.0.0.1:9100")
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := process.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot send interrupt: %v", err)
+	}
+
+	// A failing shutdown hook is returned by Run instead of crashing.
+	select {
+	case err := <-done:
+		if !errors.Is(err, hookErr) {
+			t.Errorf("expected Run to return the shutdown hook error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Run to return on interrupt")
+	}
+}
+
+func TestPulse_RunErrors(t *testing.T) {
+	app := New()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run("127.0.0.1:9094")
+	}()
+	waitForServer(t, "127.0.0.1:9094")
+
+	// The address is already in use.
+	if err := New().Run("127.0.0.1:9094"); err == nil {
+		t.Errorf("expected an error when the address is in use")
+	}
+
+	if err := app.Stop(); err != nil {
+		t.Errorf("failed to stop server: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("expected Run to return nil once stopped, got %v", err)
+	}
+}
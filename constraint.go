@@ -0,0 +1,107 @@
+package pulse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Constraint reports whether a param value is accepted by a route. Requests
+// whose param values are rejected fall through to the next matching route.
+type Constraint func(value string) bool
+
+var builtinConstraints = map[string]Constraint{
+	"int": func(value string) bool {
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	},
+	"uint": func(value string) bool {
+		_, err := strconv.ParseUint(value, 10, 64)
+		return err == nil
+	},
+	"float": func(value string) bool {
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	},
+	"bool": func(value string) bool {
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	},
+	"alpha": func(value string) bool {
+		for i := 0; i < len(value); i++ {
+			if !isAlpha(value[i]) {
+				return false
+			}
+		}
+		return value != ""
+	},
+	"alnum": func(value string) bool {
+		for i := 0; i < len(value); i++ {
+			if !isAlpha(value[i]) && !isDigit(value[i]) {
+				return false
+			}
+		}
+		return value != ""
+	},
+	"uuid": func(value string) bool {
+		if len(value) != 36 {
+			return false
+		}
+		for i := 0; i < len(value); i++ {
+			switch i {
+			case 8, 13, 18, 23:
+				if value[i] != '-' {
+					return false
+				}
+			default:
+				if !isHex(value[i]) {
+					return false
+				}
+			}
+		}
+		return true
+	},
+	"date": func(value string) bool {
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	},
+}
+
+// Constraint registers a named constraint that routes can reference as
+// ":param<name>". It must be registered before the routes that use it and
+// takes precedence over the built-in int, uint, float, bool, alpha, alnum,
+// uuid and date constraints.
+func (r *Router) Constraint(name string, constraint Constraint) {
+	r.constraints[name] = constraint
+}
+
+// constraint resolves the spec of a param constraint. Specs that are not the
+// name of a registered or built-in constraint are compiled as a regular
+// expression that must match the whole value.
+func (r *Router) constraint(spec string) Constraint {
+	if constraint, ok := r.constraints[spec]; ok {
+		return constraint
+	}
+	if constraint, ok := builtinConstraints[spec]; ok {
+		return constraint
+	}
+
+	re, err := regexp.Compile("^(?:" + spec + ")$")
+	if err != nil {
+		panic(fmt.Sprintf("pulse: invalid param constraint %q: %v", spec, err))
+	}
+	return re.MatchString
+}
+
+func isAlpha(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return isDigit(c) || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
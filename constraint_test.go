@@ -0,0 +1,104 @@
+package pulse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinConstraints(t *testing.T) {
+	tests := []struct {
+		name    string
+		valid   []string
+		invalid []string
+	}{
+		{"int", []string{"0", "42", "-7"}, []string{"", "4.2", "abc", "99999999999999999999"}},
+		{"uint", []string{"0", "42"}, []string{"-7", "abc"}},
+		{"float", []string{"4.2", "-1", "1e3"}, []string{"", "abc"}},
+		{"bool", []string{"true", "false", "1", "0"}, []string{"yes", ""}},
+		{"alpha", []string{"abc", "ABC"}, []string{"", "abc1", "a-b"}},
+		{"alnum", []string{"abc1", "A2"}, []string{"", "a-b", "a_b"}},
+		{"uuid", []string{"123e4567-e89b-12d3-a456-426614174000"}, []string{"123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400z"}},
+		{"date", []string{"2023-04-30"}, []string{"2023-02-30", "30-04-2023", "2023-4-3"}},
+	}
+
+	for _, tt := range tests {
+		constraint := builtinConstraints[tt.name]
+		for _, value := range tt.valid {
+			if !constraint(value) {
+				t.Errorf("%s: expected %q to be valid", tt.name, value)
+			}
+		}
+		for _, value := range tt.invalid {
+			if constraint(value) {
+				t.Errorf("%s: expected %q to be invalid", tt.name, value)
+			}
+		}
+	}
+}
+
+func TestRouter_Constraint(t *testing.T) {
+	router := NewRouter()
+	router.Constraint("even", func(value string) bool {
+		return strings.HasSuffix(value, "0") || strings.HasSuffix(value, "2") || strings.HasSuffix(value, "4")
+	})
+
+	route := func(name string) Handler {
+		return func(ctx *Context) error {
+			ctx.String(name)
+			return nil
+		}
+	}
+	router.Get("/users/:id<int>", route("id"))
+	router.Get("/users/:name", route("name"))
+	router.Get("/posts/:slug<[a-z0-9-]+>", route("slug"))
+	router.Get("/at/:date<date>", route("date"))
+	router.Get("/pages/:page<even>", route("even"))
+	router.Get("/pages/:page<int>?", route("page"))
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/users/42", http.StatusOK, "id"},
+		{"/users/john", http.StatusOK, "name"},
+		{"/posts/hello-world-2", http.StatusOK, "slug"},
+		{"/posts/Hello_World", http.StatusNotFound, ""},
+		{"/at/2023-04-30", http.StatusOK, "date"},
+		{"/at/yesterday", http.StatusNotFound, ""},
+		{"/pages/4", http.StatusOK, "even"},
+		{"/pages/3", http.StatusOK, "page"},
+		{"/pages", http.StatusOK, "page"},
+		{"/pages/last", http.StatusNotFound, ""},
+	}
+
+	handler := RouterHandler(router)
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if rec.Code != tt.status {
+			t.Errorf("%s: unexpected status code: got %d, want %d", tt.path, rec.Code, tt.status)
+		}
+		if tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("%s: unexpected body: got %q, want %q", tt.path, rec.Body.String(), tt.body)
+		}
+	}
+
+	if route := router.routes[http.MethodGet][0]; route.ParamNames[0] != "id" {
+		t.Errorf("unexpected param name: %q", route.ParamNames[0])
+	}
+}
+
+func TestRouter_InvalidConstraint(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected an invalid constraint to panic")
+		}
+	}()
+
+	router := NewRouter()
+	router.Get("/users/:id<[0-9>", func(ctx *Context) error { return nil })
+}
@@ -3,22 +3,31 @@ package pulse
 import (
 	"context"
 	"encoding/json"
+	"math"
 	"net/http"
 	"strings"
 	"time"
 )
 
-type handlerFunc func(ctx *Context) error
-
+// Context carries the request and response of a single request through its
+// handlers. Contexts created by RouterHandler are pooled and reset once the
+// handlers return, so a Context, and the params, cookies and locals read
+// from it, must not be retained or used from other goroutines after its
+// handler has returned. Copy the values that outlive the request instead.
 type Context struct {
 	ResponseWriter http.ResponseWriter
 	Response       http.Response
 	Request        *http.Request
 	Params         map[string]string
 	paramValues    []string
-	handlers       []handlerFunc
+	handlers       []Handler
 	handlerIdx     int
 	Cookies        []*http.Cookie
+	locals         map[string]interface{}
+	route          *Route
+	router         *Router
+	writer         responseWriter
+	wrapped        ResponseWriter
 }
 
 func (c *Context) Write(p []byte) (n int, err error) {
@@ -37,16 +46,39 @@ type Cookie struct {
 	SameSite http.SameSite
 }
 
+// abortIndex is set as the handler index by Abort. It is far above any chain
+// length so that the chain is over even once Next increments it.
+const abortIndex = math.MaxInt32 >> 1
+
 // NewContext returns a new Context.
 func NewContext(w http.ResponseWriter, req *http.Request) *Context {
-	return &Context{
-		ResponseWriter: w,
-		Request:        req,
-		Params:         make(map[string]string),
-		paramValues:    make([]string, 0, 10),
-		handlers:       nil,
-		handlerIdx:     -1,
+	c := &Context{
+		Request:     req,
+		Params:      make(map[string]string),
+		paramValues: make([]string, 0, 10),
+		handlers:    nil,
+		handlerIdx:  -1,
+	}
+	c.setResponseWriter(w)
+	return c
+}
+
+// setResponseWriter wraps w in the ResponseWriter of the Context.
+func (c *Context) setResponseWriter(w http.ResponseWriter) {
+	c.writer.reset(w)
+	if w == nil {
+		c.wrapped = &c.writer
+		c.ResponseWriter = nil
+		return
 	}
+	c.wrapped = c.writer.wrap()
+	c.ResponseWriter = c.wrapped
+}
+
+// Writer returns the ResponseWriter of the Context, which tracks the status
+// and size of the response.
+func (c *Context) Writer() ResponseWriter {
+	return c.wrapped
 }
 
 // WithParams sets the params for the context.
@@ -60,6 +92,12 @@ func (c *Context) Param(key string) string {
 	return c.Params[key]
 }
 
+// Route returns the route matched by the request, or nil when no route
+// matched.
+func (c *Context) Route() *Route {
+	return c.route
+}
+
 // Query returns the query value for the given key.
 func (c *Context) Query(key string) string {
 	return c.Request.URL.Query().Get(key)
@@ -83,23 +121,81 @@ func (c *Context) GetValue(key string) string {
 	return c.Request.Context().Value(key).(string)
 }
 
-// Next calls the next handler in the chain.
+// SetLocal stores a value for the lifetime of the request.
+func (c *Context) SetLocal(key string, value interface{}) {
+	if c.locals == nil {
+		c.locals = make(map[string]interface{})
+	}
+	c.locals[key] = value
+}
+
+// GetLocal returns the value stored for the given key with SetLocal.
+func (c *Context) GetLocal(key string) interface{} {
+	return c.locals[key]
+}
+
+// Next runs the remaining handlers of the chain, middlewares included, and
+// returns once they are done, so code placed after Next runs on the way back
+// out. Handlers that return without calling Next are followed by the next
+// handler in the chain. The chain stops at the first handler returning an
+// error, which Next returns, or when a handler calls Abort. A failed chain
+// is aborted, so it does not resume once a middleware handles the error.
 func (c *Context) Next() error {
 	c.handlerIdx++
-	if c.handlerIdx < len(c.handlers) {
-		return c.handlers[c.handlerIdx](c)
+	for c.handlerIdx < len(c.handlers) {
+		if err := c.handlers[c.handlerIdx](c); err != nil {
+			c.handlerIdx = abortIndex
+			return err
+		}
+		c.handlerIdx++
 	}
 	return nil
 }
 
-// Reset resets the Context.
+// Reset clears the request, response, params, handlers, cookies and locals of
+// the Context so it can be reused for another request.
 func (c *Context) Reset() {
+	c.setResponseWriter(nil)
+	c.Response = http.Response{}
+	c.Request = nil
+	if c.Params == nil {
+		c.Params = make(map[string]string)
+	}
+	for key := range c.Params {
+		delete(c.Params, key)
+	}
+	c.paramValues = c.paramValues[:0]
+	c.route = nil
+	c.handlers = nil
 	c.handlerIdx = -1
+	for i := range c.Cookies {
+		c.Cookies[i] = nil
+	}
+	c.Cookies = c.Cookies[:0]
+	for key := range c.locals {
+		delete(c.locals, key)
+	}
 }
 
-// Abort aborts the chain.
+// handleError passes the error to the error handler of the router serving the
+// request, or to DefaultErrorHandler for a Context made outside of a router.
+func (c *Context) handleError(err error) {
+	if c.router != nil {
+		c.router.errorHandler(c, err)
+		return
+	}
+	DefaultErrorHandler(c, err)
+}
+
+// Abort prevents the remaining handlers of the chain from running. Handlers
+// that already called Next still resume once it returns.
 func (c *Context) Abort() {
-	c.handlerIdx = len(c.handlers)
+	c.handlerIdx = abortIndex
+}
+
+// IsAborted reports whether Abort was called.
+func (c *Context) IsAborted() bool {
+	return c.handlerIdx >= abortIndex
 }
 
 // SetCookie sets a cookie with the given name, value, and options.
@@ -1,9 +1,11 @@
 package pulse
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -188,6 +190,53 @@ func TestContext_Reset(t *testing.T) {
 	}
 }
 
+func TestContext_ResetClearsRequestState(t *testing.T) {
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/", nil)
+	ctx := NewContext(w, r)
+
+	ctx.Params["id"] = "1"
+	ctx.paramValues = append(ctx.paramValues, "1")
+	ctx.handlers = []Handler{func(ctx *Context) error { return nil }}
+	ctx.SetCookie(&Cookie{Name: "session", Value: "secret"})
+	ctx.SetLocal("user", "john")
+	ctx.Response.StatusCode = http.StatusTeapot
+
+	ctx.Reset()
+
+	if ctx.ResponseWriter != nil || ctx.Request != nil {
+		t.Errorf("expected request and response writer to be cleared")
+	}
+	if len(ctx.Params) != 0 || len(ctx.paramValues) != 0 {
+		t.Errorf("expected params to be cleared, got %v and %v", ctx.Params, ctx.paramValues)
+	}
+	if ctx.handlers != nil {
+		t.Errorf("expected handlers to be cleared")
+	}
+	if len(ctx.Cookies) != 0 || ctx.GetCookie("session") != "" {
+		t.Errorf("expected cookies to be cleared, got %v", ctx.Cookies)
+	}
+	if ctx.GetLocal("user") != nil {
+		t.Errorf("expected locals to be cleared, got %v", ctx.GetLocal("user"))
+	}
+	if ctx.Response.StatusCode != 0 {
+		t.Errorf("expected response to be cleared, got status %d", ctx.Response.StatusCode)
+	}
+}
+
+func TestContext_Locals(t *testing.T) {
+	ctx := NewContext(nil, nil)
+
+	if value := ctx.GetLocal("user"); value != nil {
+		t.Errorf("expected no value, got %v", value)
+	}
+
+	ctx.SetLocal("user", "john")
+	if value := ctx.GetLocal("user"); value != "john" {
+		t.Errorf("expected %q, got %v", "john", value)
+	}
+}
+
 func TestContext_Status(t *testing.T) {
 	w := httptest.NewRecorder()
 	ctx := NewContext(w, nil)
@@ -275,3 +324,97 @@ func TestContext_BodyParser(t *testing.T) {
 		}
 	}
 }
+
+func TestContext_NextChain(t *testing.T) {
+	var calls []string
+	record := func(name string) Handler {
+		return func(ctx *Context) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	router := NewRouter()
+	router.Use(http.MethodGet, MiddlewareFunc(func(handler Handler) Handler {
+		return func(ctx *Context) error {
+			calls = append(calls, "wrap:before")
+			err := handler(ctx)
+			calls = append(calls, "wrap:after")
+			return err
+		}
+	}))
+	router.Get("/next", func(ctx *Context) error {
+		calls = append(calls, "timing:before")
+		err := ctx.Next()
+		calls = append(calls, "timing:after")
+		return err
+	}, record("first"), record("second"))
+	router.Get("/abort", func(ctx *Context) error {
+		calls = append(calls, "guard")
+		ctx.Abort()
+		return nil
+	}, record("handler"))
+	router.Get("/error", func(ctx *Context) error {
+		calls = append(calls, "failing")
+		return errors.New("failed")
+	}, record("handler"))
+
+	guarded := router.Get("/guarded", record("handler"))
+	guarded.Use(MiddlewareFunc(func(handler Handler) Handler {
+		return func(ctx *Context) error {
+			calls = append(calls, "deny")
+			return nil
+		}
+	}))
+
+	swallowed := router.Get("/swallowed", func(ctx *Context) error {
+		calls = append(calls, "failing")
+		return errors.New("failed")
+	}, record("handler"))
+	swallowed.Use(MiddlewareFunc(func(handler Handler) Handler {
+		return func(ctx *Context) error {
+			if err := handler(ctx); err != nil {
+				calls = append(calls, "swallow")
+			}
+			return nil
+		}
+	}))
+
+	tests := []struct {
+		path  string
+		calls []string
+	}{
+		{"/next", []string{"wrap:before", "timing:before", "first", "second", "timing:after", "wrap:after"}},
+		{"/swallowed", []string{"wrap:before", "failing", "swallow", "wrap:after"}},
+		{"/abort", []string{"wrap:before", "guard", "wrap:after"}},
+		{"/error", []string{"wrap:before", "failing", "wrap:after"}},
+		{"/guarded", []string{"wrap:before", "deny", "wrap:after"}},
+	}
+
+	handler := RouterHandler(router)
+	for _, tt := range tests {
+		calls = nil
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if !reflect.DeepEqual(calls, tt.calls) {
+			t.Errorf("%s: expected calls %v, got %v", tt.path, tt.calls, calls)
+		}
+	}
+}
+
+func TestContext_IsAborted(t *testing.T) {
+	ctx := NewContext(nil, nil)
+	if ctx.IsAborted() {
+		t.Errorf("expected a new context not to be aborted")
+	}
+
+	ctx.Abort()
+	if !ctx.IsAborted() {
+		t.Errorf("expected context to be aborted")
+	}
+
+	ctx.Reset()
+	if ctx.IsAborted() {
+		t.Errorf("expected a reset context not to be aborted")
+	}
+}
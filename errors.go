@@ -0,0 +1,80 @@
+package pulse
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrorHandler handles the errors returned by the handlers of a request.
+type ErrorHandler func(ctx *Context, err error)
+
+// HTTPError is an error that handlers can return to respond with a specific
+// status code and message. The internal error, if any, is not sent to the
+// client.
+type HTTPError struct {
+	Code     int
+	Message  string
+	Internal error
+}
+
+// NewHTTPError returns an HTTPError with the given status code. The message
+// defaults to the status text of the code.
+func NewHTTPError(code int, message ...string) *HTTPError {
+	e := &HTTPError{Code: code, Message: http.StatusText(code)}
+	if len(message) > 0 {
+		e.Message = message[0]
+	}
+	return e
+}
+
+// Error returns the status code and message of the error, followed by the
+// internal error if any.
+func (e *HTTPError) Error() string {
+	if e.Internal != nil {
+		return fmt.Sprintf("code=%d, message=%s, internal=%v", e.Code, e.Message, e.Internal)
+	}
+	return fmt.Sprintf("code=%d, message=%s", e.Code, e.Message)
+}
+
+// Unwrap returns the internal error.
+func (e *HTTPError) Unwrap() error {
+	return e.Internal
+}
+
+// WithInternal sets the internal error and returns the HTTPError.
+func (e *HTTPError) WithInternal(err error) *HTTPError {
+	e.Internal = err
+	return e
+}
+
+// DefaultErrorHandler responds with the status code and message of an
+// HTTPError, and with 500 Internal Server Error for any other error or for an
+// HTTPError with an invalid code. Nothing is written when the handlers already
+// started the response.
+func DefaultErrorHandler(ctx *Context, err error) {
+	if ctx.Writer().Written() {
+		return
+	}
+
+	code, message := errorResponse(err)
+	http.Error(ctx.ResponseWriter, message, code)
+}
+
+// errorResponse returns the status code and message of the response to the
+// error. Codes outside of 100-999 cannot be written and are replaced with 500.
+func errorResponse(err error) (int, string) {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)
+	}
+
+	code, message := httpErr.Code, httpErr.Message
+	if code < 100 || code > 999 {
+		code = http.StatusInternalServerError
+	}
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	return code, message
+}
@@ -0,0 +1,89 @@
+package pulse
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewHTTPError(t *testing.T) {
+	err := NewHTTPError(http.StatusNotFound)
+	if err.Code != http.StatusNotFound || err.Message != "Not Found" {
+		t.Errorf("unexpected error: %+v", err)
+	}
+
+	err = NewHTTPError(http.StatusBadRequest, "invalid id")
+	if err.Message != "invalid id" {
+		t.Errorf("unexpected message: %q", err.Message)
+	}
+	if err.Error() != "code=400, message=invalid id" {
+		t.Errorf("unexpected error string: %q", err.Error())
+	}
+
+	cause := errors.New("strconv: parse error")
+	err.WithInternal(cause)
+	if !errors.Is(err, cause) {
+		t.Errorf("expected error to wrap its internal error")
+	}
+	if err.Error() != "code=400, message=invalid id, internal=strconv: parse error" {
+		t.Errorf("unexpected error string: %q", err.Error())
+	}
+}
+
+func TestDefaultErrorHandler(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		body   string
+	}{
+		{errors.New("database is down"), http.StatusInternalServerError, "Internal Server Error"},
+		{NewHTTPError(http.StatusForbidden), http.StatusForbidden, "Forbidden"},
+		{fmt.Errorf("loading user: %w", NewHTTPError(http.StatusNotFound, "no such user")), http.StatusNotFound, "no such user"},
+		{&HTTPError{Message: "x"}, http.StatusInternalServerError, "x"},
+		{NewHTTPError(0), http.StatusInternalServerError, "Internal Server Error"},
+		{NewHTTPError(1000), http.StatusInternalServerError, "Internal Server Error"},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		DefaultErrorHandler(NewContext(rec, httptest.NewRequest(http.MethodGet, "/", nil)), tt.err)
+
+		if rec.Code != tt.status {
+			t.Errorf("%v: unexpected status code: got %d, want %d", tt.err, rec.Code, tt.status)
+		}
+		if body := strings.TrimSpace(rec.Body.String()); body != tt.body {
+			t.Errorf("%v: unexpected body: got %q, want %q", tt.err, body, tt.body)
+		}
+	}
+}
+
+func TestConfig_ErrorHandler(t *testing.T) {
+	var handled error
+	app := New(Config{
+		ErrorHandler: func(ctx *Context, err error) {
+			handled = err
+			_, _ = ctx.JSON(http.StatusTeapot, map[string]string{"error": err.Error()})
+		},
+	})
+
+	failure := errors.New("failure")
+	app.Router.Get("/", func(ctx *Context) error {
+		return failure
+	})
+
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if handled != failure {
+		t.Errorf("expected error handler to receive %v, got %v", failure, handled)
+	}
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("unexpected status code: got %d, want %d", rec.Code, http.StatusTeapot)
+	}
+	if body := rec.Body.String(); body != `{"error":"failure"}` {
+		t.Errorf("unexpected body: %q", body)
+	}
+}
@@ -1,9 +1,10 @@
 module github.com/gopulse/pulse
 
-go 1.19
+go 1.21
+
+require github.com/common-nighthawk/go-figure v0.0.0-20210622060536-734e95fb86be
 
 require (
-	github.com/common-nighthawk/go-figure v0.0.0-20210622060536-734e95fb86be // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
 	github.com/go-playground/validator/v10 v10.12.0 // indirect
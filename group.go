@@ -1,49 +1,72 @@
 package pulse
 
+import (
+	"io/fs"
+	"net/http"
+)
+
 type Group struct {
 	Prefix string
 	Router *Router
+
+	parent      *Group
+	middlewares []Middleware
 }
 
+// Group returns a nested group that inherits the prefix and middlewares of g.
 func (g *Group) Group(prefix string) *Group {
 	return &Group{
 		Prefix: g.Prefix + prefix,
 		Router: g.Router,
+		parent: g,
 	}
 }
 
-func (g *Group) Use(middleware Middleware) {
-	g.Router.Use(g.Prefix, middleware)
+// Use adds middlewares that apply to the routes of the group and of its nested
+// groups, after the router-wide middlewares.
+func (g *Group) Use(middlewares ...Middleware) {
+	g.middlewares = append(g.middlewares, middlewares...)
+	g.Router.rebuild()
+}
+
+func (g *Group) add(method, path string, handlers ...Handler) *Route {
+	return g.Router.add(method, g.Prefix+path, g, handlers)
 }
 
-func (g *Group) GET(path string, handlers ...Handler) {
-	g.Router.Get(g.Prefix+path, handlers...)
+func (g *Group) GET(path string, handlers ...Handler) *Route {
+	return g.add(http.MethodGet, path, handlers...)
 }
 
-func (g *Group) POST(path string, handlers ...Handler) {
-	g.Router.Post(g.Prefix+path, handlers...)
+func (g *Group) POST(path string, handlers ...Handler) *Route {
+	return g.add(http.MethodPost, path, handlers...)
 }
 
-func (g *Group) PUT(path string, handlers ...Handler) {
-	g.Router.Put(g.Prefix+path, handlers...)
+func (g *Group) PUT(path string, handlers ...Handler) *Route {
+	return g.add(http.MethodPut, path, handlers...)
 }
 
-func (g *Group) DELETE(path string, handlers ...Handler) {
-	g.Router.Delete(g.Prefix+path, handlers...)
+func (g *Group) DELETE(path string, handlers ...Handler) *Route {
+	return g.add(http.MethodDelete, path, handlers...)
 }
 
-func (g *Group) PATCH(path string, handlers ...Handler) {
-	g.Router.Patch(g.Prefix+path, handlers...)
+func (g *Group) PATCH(path string, handlers ...Handler) *Route {
+	return g.add(http.MethodPatch, path, handlers...)
 }
 
-func (g *Group) OPTIONS(path string, handlers ...Handler) {
-	g.Router.Options(g.Prefix+path, handlers...)
+func (g *Group) OPTIONS(path string, handlers ...Handler) *Route {
+	return g.add(http.MethodOptions, path, handlers...)
 }
 
-func (g *Group) HEAD(path string, handlers ...Handler) {
-	g.Router.Head(g.Prefix+path, handlers...)
+func (g *Group) HEAD(path string, handlers ...Handler) *Route {
+	return g.add(http.MethodHead, path, handlers...)
 }
 
 func (g *Group) Static(path, root string, config *Static) {
-	g.Router.Static(g.Prefix+path, root, config)
+	g.Router.static(g.Prefix+path, root, config, g)
+}
+
+// StaticFS serves the files of the file system under the path of the group.
+// See Router.StaticFS.
+func (g *Group) StaticFS(path string, fsys fs.FS, config *Static) {
+	g.Router.staticFS(g.Prefix+path, http.FS(fsys), config, g)
 }
@@ -1,6 +1,9 @@
 package pulse
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -53,3 +56,52 @@ func TestRouter_Group(t *testing.T) {
 
 	app.Router = router
 }
+
+func TestGroup_Use(t *testing.T) {
+	var calls []string
+	record := func(name string) MiddlewareFunc {
+		return func(handler Handler) Handler {
+			return func(ctx *Context) error {
+				calls = append(calls, name)
+				return handler(ctx)
+			}
+		}
+	}
+	handler := func(ctx *Context) error {
+		calls = append(calls, "handler")
+		return nil
+	}
+
+	router := NewRouter()
+	router.Use(http.MethodGet, record("get"))
+	router.Use("*", record("global"))
+
+	api := &Group{Prefix: "/api", Router: router}
+	api.Use(record("api"))
+	v1 := api.Group("/v1")
+	v1.Use(record("v1"))
+
+	v1.GET("/users", handler).Use(record("route"))
+	api.GET("/status", handler)
+	router.Get("/health", handler)
+
+	tests := []struct {
+		path  string
+		calls []string
+	}{
+		{"/api/v1/users", []string{"global", "get", "api", "v1", "route", "handler"}},
+		{"/api/status", []string{"global", "get", "api", "handler"}},
+		{"/health", []string{"global", "get", "handler"}},
+		{"/api/missing", []string{"global", "get"}},
+	}
+
+	for _, tt := range tests {
+		calls = nil
+		rec := httptest.NewRecorder()
+		RouterHandler(router).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if !reflect.DeepEqual(calls, tt.calls) {
+			t.Errorf("%s: expected calls %v, got %v", tt.path, tt.calls, calls)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package pulse
+
+import (
+	"fmt"
+	"net"
+)
+
+// OnStart registers a hook run by Run, RunWithContext and RunTLS before the
+// server starts listening, such as to warm caches. An error aborts the startup
+// and is returned by Run.
+func (p *Pulse) OnStart(hook func() error) {
+	p.startHooks = append(p.startHooks, hook)
+}
+
+// OnListen registers a hook run with the address of the listener once the
+// server listens and before it serves requests, such as to register the app
+// with a service discovery. An error aborts the startup and is returned by
+// Run.
+func (p *Pulse) OnListen(hook func(addr string) error) {
+	p.listenHooks = append(p.listenHooks, hook)
+}
+
+// OnShutdown registers a hook run by Stop once the server is shut down, such
+// as to flush buffers or close database connections. The errors of the hooks
+// are returned by Stop.
+func (p *Pulse) OnShutdown(hook func() error) {
+	p.shutdownHooks = append(p.shutdownHooks, hook)
+}
+
+// OnRoute registers a hook run for every route registered on the router of
+// the app from now on, once the route is complete and before it is served,
+// such as to log or check the routes. A route rejected by a hook is not
+// served, and the first error of the hooks is returned by Run instead of
+// starting the server. Routes registered on a router before it is assigned to
+// Pulse.Router are passed to the hooks when the app first serves it.
+func (p *Pulse) OnRoute(hook func(route *Route) error) {
+	p.routeHooks = append(p.routeHooks, hook)
+}
+
+// listen checks the route hooks, runs the start hooks, listens on the address and runs the
+// listen hooks. The listener is closed when a listen hook fails.
+func (p *Pulse) listen(address string) (net.Listener, error) {
+	if p.routeHookErr != nil {
+		return nil, p.routeHookErr
+	}
+
+	for _, hook := range p.startHooks {
+		if err := hook(); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen(p.config.Network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	for _, hook := range p.listenHooks {
+		if err := hook(listener.Addr().String()); err != nil {
+			listener.Close()
+			return nil, err
+		}
+	}
+	return listener, nil
+}
+
+// runRouteHooks runs the route hooks for the route and reports whether they
+// all accepted it. The first error is kept for Run.
+func (p *Pulse) runRouteHooks(route *Route) bool {
+	for _, hook := range p.routeHooks {
+		if err := hook(route); err != nil {
+			if p.routeHookErr == nil {
+				p.routeHookErr = fmt.Errorf("route hook failed for %s %q: %w", route.Method, route.Path, err)
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// attachRouter makes the router of the app run the route hooks of the app for
+// the routes registered on it, and passes them the routes it already has.
+func (p *Pulse) attachRouter() {
+	router := p.Router
+	if router.app == p {
+		return
+	}
+	router.app = p
+
+	pending := router.pending
+	router.pending = nil
+	for _, route := range pending {
+		if !p.runRouteHooks(route) {
+			router.remove(route)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package pulse
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPulse_Hooks(t *testing.T) {
+	app := New()
+
+	var events []string
+	app.OnStart(func() error {
+		events = append(events, "start")
+		return nil
+	})
+	app.OnListen(func(addr string) error {
+		events = append(events, "listen "+addr)
+		return nil
+	})
+	app.OnShutdown(func() error {
+		events = append(events, "shutdown")
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run("127.0.0.1:9095")
+	}()
+	waitForServer(t, "127.0.0.1:9095")
+
+	if err := app.Stop(); err != nil {
+		t.Errorf("failed to stop server: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("expected Run to return nil once stopped, got %v", err)
+	}
+
+	expected := []string{"start", "listen 127.0.0.1:9095", "shutdown"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events %v, got %v", expected, events)
+	}
+}
+
+func TestPulse_OnStartError(t *testing.T) {
+	app := New()
+
+	startErr := errors.New("cache warmup failed")
+	app.OnStart(func() error {
+		return startErr
+	})
+	listened := false
+	app.OnListen(func(addr string) error {
+		listened = true
+		return nil
+	})
+
+	if err := app.Run("127.0.0.1:9096"); !errors.Is(err, startErr) {
+		t.Errorf("expected Run to return the start hook error, got %v", err)
+	}
+	if listened {
+		t.Errorf("expected the listen hooks not to run")
+	}
+}
+
+func TestPulse_OnListenError(t *testing.T) {
+	app := New()
+
+	listenErr := errors.New("registration failed")
+	app.OnListen(func(addr string) error {
+		return listenErr
+	})
+
+	if err := app.Run("127.0.0.1:9097"); !errors.Is(err, listenErr) {
+		t.Errorf("expected Run to return the listen hook error, got %v", err)
+	}
+
+	// The listener is closed when the startup is aborted.
+	listener, err := net.Listen("tcp", "127.0.0.1:9097")
+	if err != nil {
+		t.Fatalf("expected the address to be free: %v", err)
+	}
+	listener.Close()
+}
+
+func TestPulse_OnRoute(t *testing.T) {
+	app := New()
+
+	var routes []string
+	app.OnRoute(func(route *Route) error {
+		if route.group != nil {
+			routes = append(routes, route.Method+" "+route.Path+" (group)")
+			return nil
+		}
+		routes = append(routes, route.Method+" "+route.Path)
+		return nil
+	})
+
+	app.Router.Get("/users/:id", func(ctx *Context) error { return nil })
+	(&Group{Prefix: "/api", Router: app.Router}).POST("/items", func(ctx *Context) error { return nil })
+
+	// The hooks run as the routes are registered.
+	expected := []string{"GET /users/:id", "POST /api/items (group)"}
+	if !reflect.DeepEqual(routes, expected) {
+		t.Errorf("expected routes %v, got %v", expected, routes)
+	}
+
+	hookErr := errors.New("deletes are not allowed")
+	app.OnRoute(func(route *Route) error {
+		if route.Method == http.MethodDelete {
+			return hookErr
+		}
+		return nil
+	})
+	app.Router.Delete("/users/:id", func(ctx *Context) error { return nil })
+	app.Router.Delete("/items/:id", func(ctx *Context) error { return nil })
+
+	// A rejected route is not served.
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/users/42", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected the rejected route not to be served, got %d", rec.Code)
+	}
+
+	started := false
+	app.OnStart(func() error {
+		started = true
+		return nil
+	})
+	err := app.Run("127.0.0.1:9098")
+	if !errors.Is(err, hookErr) {
+		t.Errorf("expected Run to return the route hook error, got %v", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), `"/users/:id"`) {
+		t.Errorf("expected the first failing route in the error, got %v", err)
+	}
+	if started {
+		t.Errorf("expected the start hooks not to run")
+	}
+}
+
+func TestPulse_OnRouteAssignedRouter(t *testing.T) {
+	app := New()
+
+	var routes []string
+	hookErr := errors.New("deletes are not allowed")
+	app.OnRoute(func(route *Route) error {
+		routes = append(routes, route.Method+" "+route.Path)
+		if route.Method == http.MethodDelete {
+			return hookErr
+		}
+		return nil
+	})
+
+	router := NewRouter()
+	router.Post("/users", func(ctx *Context) error { return nil })
+	router.Delete("/users/:id", func(ctx *Context) error { return nil })
+	router.Get("/users/:id", func(ctx *Context) error { return nil })
+	app.Router = router
+	if len(routes) != 0 {
+		t.Fatalf("expected the hooks not to run before the router is served, got %v", routes)
+	}
+
+	// The routes registered before the router was assigned are passed to the
+	// hooks once, in the order they were registered.
+	handler := app.Handler()
+	app.Handler()
+	router.Put("/users/:id", func(ctx *Context) error { return nil })
+
+	expected := []string{"POST /users", "DELETE /users/:id", "GET /users/:id", "PUT /users/:id"}
+	if !reflect.DeepEqual(routes, expected) {
+		t.Errorf("expected routes %v, got %v", expected, routes)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/users/42", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected the rejected route not to be served, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected the accepted route to be served, got %d", rec.Code)
+	}
+
+	if err := app.Run("127.0.0.1:9099"); !errors.Is(err, hookErr) {
+		t.Errorf("expected Run to return the route hook error, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package pulse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+)
+
+// LogFormat is the output format of LoggerMiddleware.
+type LogFormat int
+
+const (
+	// LogFormatText writes each request as a line of key=value pairs
+	LogFormatText LogFormat = iota
+
+	// LogFormatJSON writes each request as a JSON object
+	LogFormatJSON
+)
+
+// LoggerConfig configures LoggerMiddleware.
+type LoggerConfig struct {
+	// Output is where requests are logged, it defaults to os.Stdout
+	Output io.Writer
+
+	// Format is the format of the logged requests, it defaults to
+	// LogFormatText
+	Format LogFormat
+
+	// Handler receives the log records instead of Output and Format, so that
+	// requests can be logged through any log/slog handler
+	Handler slog.Handler
+
+	// Skip reports whether the request should not be logged
+	Skip func(ctx *Context) bool
+
+	// RequestIDHeader is the header holding the request ID, it defaults to
+	// DefaultRequestIDHeader
+	RequestIDHeader string
+
+	// TrustedProxies are the IPs or CIDR ranges of the proxies allowed to
+	// set the client IP through the X-Forwarded-For and X-Real-IP headers.
+	// The headers are ignored by default and the IP of the connection is
+	// logged, as any client can set them.
+	TrustedProxies []string
+}
+
+// DefaultRequestIDHeader is the default header read by LoggerMiddleware for the
+// request ID.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// LoggerMiddleware logs the method, path, route pattern, status, bytes
+// written, latency, client IP and request ID of every request once the rest
+// of the chain returns. Server errors are logged at the error level and
+// client errors at the warn level.
+//
+// An error returned by the rest of the chain is handled by the error handler
+// of the app before the request is logged, so that the logged status is the
+// one sent to the client, and is not returned to the previous middlewares.
+func LoggerMiddleware(config ...LoggerConfig) MiddlewareFunc {
+	cfg := LoggerConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Output == nil {
+		cfg.Output = os.Stdout
+	}
+	if cfg.RequestIDHeader == "" {
+		cfg.RequestIDHeader = DefaultRequestIDHeader
+	}
+	if cfg.Handler == nil {
+		if cfg.Format == LogFormatJSON {
+			cfg.Handler = slog.NewJSONHandler(cfg.Output, nil)
+		} else {
+			cfg.Handler = slog.NewTextHandler(cfg.Output, nil)
+		}
+	}
+	logger := slog.New(cfg.Handler)
+	proxies := parseTrustedProxies(cfg.TrustedProxies)
+
+	return func(handler Handler) Handler {
+		return func(ctx *Context) error {
+			if cfg.Skip != nil && cfg.Skip(ctx) {
+				return handler(ctx)
+			}
+
+			start := time.Now()
+			err := handler(ctx)
+			if err != nil {
+				ctx.handleError(err)
+			}
+			latency := time.Since(start)
+
+			w := ctx.Writer()
+			status := w.Status()
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			} else if status >= http.StatusBadRequest {
+				level = slog.LevelWarn
+			}
+
+			pattern := ""
+			if route := ctx.Route(); route != nil {
+				pattern = route.Path
+			}
+			requestID := ctx.GetRequestHeader(cfg.RequestIDHeader)
+			if requestID == "" {
+				requestID = ctx.GetResponseHeader(cfg.RequestIDHeader)
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", ctx.Request.Method),
+				slog.String("path", ctx.Request.URL.Path),
+				slog.String("route", pattern),
+				slog.Int("status", status),
+				slog.Int("bytes", w.Size()),
+				slog.Duration("latency", latency),
+				slog.String("ip", clientIP(ctx.Request, proxies)),
+				slog.String("request_id", requestID),
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+			logger.LogAttrs(context.Background(), level, "request", attrs...)
+
+			return nil
+		}
+	}
+}
+
+// SkipPaths returns a LoggerConfig.Skip predicate skipping requests to the
+// given paths, such as health checks.
+func SkipPaths(paths ...string) func(ctx *Context) bool {
+	return func(ctx *Context) bool {
+		for _, path := range paths {
+			if ctx.Request.URL.Path == path {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// parseTrustedProxies parses the IPs and CIDR ranges of
+// LoggerConfig.TrustedProxies, panicking on invalid ones.
+func parseTrustedProxies(proxies []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			panic(fmt.Sprintf("pulse: invalid trusted proxy %q", proxy))
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes
+}
+
+// trusted reports whether the IP belongs to one of the trusted proxies.
+func trusted(ip string, proxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the IP of the client. The X-Forwarded-For and X-Real-IP
+// headers are only read when the request comes from a trusted proxy, in
+// which case the client is the last address of X-Forwarded-For that is not
+// a trusted proxy.
+func clientIP(r *http.Request, proxies []netip.Prefix) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	if !trusted(ip, proxies) {
+		return ip
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		addrs := strings.Split(forwarded, ",")
+		for i := len(addrs) - 1; i >= 0; i-- {
+			ip = strings.TrimSpace(addrs[i])
+			if !trusted(ip, proxies) {
+				break
+			}
+		}
+		return ip
+	}
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		return realIP
+	}
+	return ip
+}
@@ -0,0 +1,198 @@
+package pulse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestLoggerMiddleware_JSON(t *testing.T) {
+	var out bytes.Buffer
+	router := NewRouter()
+	router.Use("*", LoggerMiddleware(LoggerConfig{
+		Output: &out,
+		Format: LogFormatJSON,
+	}))
+	router.Get("/users/:id", func(ctx *Context) error {
+		ctx.Status(http.StatusCreated)
+		ctx.String("hello")
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.RemoteAddr = "10.0.0.1:51234"
+	req.Header.Set("X-Request-ID", "abc-123")
+	RouterHandler(router).ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("expected a JSON record, got %q: %v", out.String(), err)
+	}
+
+	expected := map[string]interface{}{
+		"level":      "INFO",
+		"msg":        "request",
+		"method":     "GET",
+		"path":       "/users/42",
+		"route":      "/users/:id",
+		"status":     float64(http.StatusCreated),
+		"bytes":      float64(5),
+		"ip":         "10.0.0.1",
+		"request_id": "abc-123",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("%s: expected %v, got %v", key, value, record[key])
+		}
+	}
+	if _, ok := record["latency"]; !ok {
+		t.Errorf("expected latency to be logged")
+	}
+}
+
+func TestLoggerMiddleware_Text(t *testing.T) {
+	var out bytes.Buffer
+	router := NewRouter()
+	router.Use("*", LoggerMiddleware(LoggerConfig{
+		Output:         &out,
+		Skip:           SkipPaths("/health"),
+		TrustedProxies: []string{"192.0.2.1", "10.0.0.0/8"},
+	}))
+	router.Get("/health", func(ctx *Context) error {
+		return nil
+	})
+	router.Get("/fail", func(ctx *Context) error {
+		return errors.New("database is down")
+	})
+
+	handler := RouterHandler(router)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	if out.Len() != 0 {
+		t.Errorf("expected skipped request not to be logged, got %q", out.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := out.String()
+	for _, field := range []string{"level=ERROR", "method=GET", "path=/fail", "route=/fail", "status=500", "ip=203.0.113.7", `error="database is down"`} {
+		if !strings.Contains(line, field) {
+			t.Errorf("expected %q in %q", field, line)
+		}
+	}
+
+	out.Reset()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if line := out.String(); !strings.Contains(line, "level=WARN") || !strings.Contains(line, "status=404") || !strings.Contains(line, "route=\"\"") {
+		t.Errorf("unexpected not found log line: %q", line)
+	}
+}
+
+func TestLoggerMiddleware_Handler(t *testing.T) {
+	var out bytes.Buffer
+	router := NewRouter()
+	router.Use("*", LoggerMiddleware(LoggerConfig{
+		Handler: slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelWarn}),
+	}))
+	router.Get("/", func(ctx *Context) error {
+		return nil
+	})
+	router.Get("/forbidden", func(ctx *Context) error {
+		return NewHTTPError(http.StatusForbidden)
+	})
+
+	handler := RouterHandler(router)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if out.Len() != 0 {
+		t.Errorf("expected info record to be filtered by the handler, got %q", out.String())
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/forbidden", nil))
+	if !strings.Contains(out.String(), `"status":403`) {
+		t.Errorf("expected forbidden request to be logged, got %q", out.String())
+	}
+}
+
+func TestLoggerMiddleware_ErrorHandler(t *testing.T) {
+	var out bytes.Buffer
+	handled := 0
+	app := New(Config{
+		ErrorHandler: func(ctx *Context, err error) {
+			handled++
+			ctx.Status(http.StatusNotFound)
+			ctx.String("custom: " + err.Error())
+		},
+	})
+	app.Router.Use("*", LoggerMiddleware(LoggerConfig{Output: &out}))
+	app.Router.Get("/users/:id", func(ctx *Context) error {
+		return errors.New("no such user")
+	})
+
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	if rec.Code != http.StatusNotFound || rec.Body.String() != "custom: no such user" {
+		t.Errorf("expected the custom error response, got %d %q", rec.Code, rec.Body.String())
+	}
+	if handled != 1 {
+		t.Errorf("expected the error handler to run once, got %d", handled)
+	}
+	line := out.String()
+	for _, field := range []string{"level=WARN", "status=404", `error="no such user"`} {
+		if !strings.Contains(line, field) {
+			t.Errorf("expected %q in %q", field, line)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := parseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"})
+
+	tests := []struct {
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		proxies    []netip.Prefix
+		want       string
+	}{
+		{"203.0.113.7:1234", "198.51.100.1", "198.51.100.2", nil, "203.0.113.7"},
+		{"192.0.2.1:1234", "198.51.100.1", "", nil, "192.0.2.1"},
+		{"203.0.113.7:1234", "198.51.100.1", "", proxies, "203.0.113.7"},
+		{"192.0.2.1:1234", "198.51.100.1", "", proxies, "198.51.100.1"},
+		{"192.0.2.1:1234", "6.6.6.6, 198.51.100.1, 10.0.0.2", "", proxies, "198.51.100.1"},
+		{"192.0.2.1:1234", "10.0.0.3, 10.0.0.2", "", proxies, "10.0.0.3"},
+		{"192.0.2.1:1234", "", "198.51.100.2", proxies, "198.51.100.2"},
+		{"[2001:db8::1]:1234", "198.51.100.1", "", proxies, "198.51.100.1"},
+		{"[::ffff:10.0.0.1]:1234", "198.51.100.1", "", proxies, "198.51.100.1"},
+		{"pipe", "198.51.100.1", "", proxies, "pipe"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tt.remoteAddr
+		if tt.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		if tt.realIP != "" {
+			req.Header.Set("X-Real-IP", tt.realIP)
+		}
+		if got := clientIP(req, tt.proxies); got != tt.want {
+			t.Errorf("clientIP(%s, %q, %q) = %q, want %q", tt.remoteAddr, tt.forwarded, tt.realIP, got, tt.want)
+		}
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected an invalid trusted proxy to panic")
+		}
+	}()
+	parseTrustedProxies([]string{"proxy.local"})
+}
@@ -1,5 +1,7 @@
 package pulse
 
+import "github.com/gopulse/pulse/constants"
+
 type MiddlewareFunc func(handler Handler) Handler
 
 type Middleware interface {
@@ -11,14 +13,83 @@ func (m MiddlewareFunc) Middleware(handler Handler) Handler {
 	return m(handler)
 }
 
+// Use adds router-wide middlewares for the given method, or for every method
+// when method is "*". Middlewares run in this order, each one wrapping the
+// next:
+//
+//  1. middlewares added with Use("*", ...)
+//  2. middlewares added with Use(method, ...) for the request method
+//  3. group middlewares added with Group.Use, from the outermost group
+//  4. route middlewares added with Route.Use
+//
+// Middlewares within each scope run in the order they were added. The not
+// found and method not allowed handlers only run through the first two.
+//
+// The middleware chain of each route is composed when routes and middlewares
+// are registered, which must therefore happen before the router serves
+// requests.
 func (r *Router) Use(method string, middlewares ...interface{}) {
 	for _, middleware := range middlewares {
-		if middlewareFunc, ok := middleware.(MiddlewareFunc); ok {
-			r.middlewares[method] = append(r.middlewares[method], middlewareFunc)
-		} else if middleware, ok := middleware.(Middleware); ok {
+		switch middleware := middleware.(type) {
+		case func(handler Handler) Handler:
+			r.middlewares[method] = append(r.middlewares[method], MiddlewareFunc(middleware))
+		case Middleware:
 			r.middlewares[method] = append(r.middlewares[method], middleware)
 		}
 	}
+	r.rebuild()
+}
+
+// Use adds middlewares that only apply to the route.
+func (r *Route) Use(middlewares ...Middleware) *Route {
+	r.middlewares = append(r.middlewares, middlewares...)
+	r.router.compose(r)
+	return r
+}
+
+// nextHandler resumes the chain of the Context. It is the handler wrapped by
+// middlewares, so that calling it runs the rest of the chain.
+func nextHandler(ctx *Context) error {
+	return ctx.Next()
+}
+
+// middlewareHandler turns the middleware into a handler of the chain. A
+// middleware that returns without calling its handler aborts the chain.
+func middlewareHandler(middleware Middleware) Handler {
+	handler := middleware.Middleware(nextHandler)
+	return func(ctx *Context) error {
+		idx := ctx.handlerIdx
+		err := handler(ctx)
+		if ctx.handlerIdx == idx {
+			ctx.Abort()
+		}
+		return err
+	}
+}
+
+// routerMiddlewares returns the router-wide middlewares for the method.
+func (r *Router) routerMiddlewares(method string) []Middleware {
+	middlewares := make([]Middleware, 0, len(r.middlewares[constants.WildcardSign])+len(r.middlewares[method]))
+	middlewares = append(middlewares, r.middlewares[constants.WildcardSign]...)
+	if method != constants.WildcardSign {
+		middlewares = append(middlewares, r.middlewares[method]...)
+	}
+	return middlewares
+}
+
+// routeMiddlewares returns every middleware that applies to the route.
+func (r *Router) routeMiddlewares(route *Route) []Middleware {
+	middlewares := r.routerMiddlewares(route.Method)
+
+	var groups []*Group
+	for g := route.group; g != nil; g = g.parent {
+		groups = append(groups, g)
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		middlewares = append(middlewares, groups[i].middlewares...)
+	}
+
+	return append(middlewares, route.middlewares...)
 }
 
 func CORSMiddleware() MiddlewareFunc {
@@ -3,6 +3,8 @@ package pulse
 import (
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -140,3 +142,39 @@ func TestMiddlewareFunc_Handle(t *testing.T) {
 		t.Errorf("Expected err to be nil, but got %v", err)
 	}
 }
+
+func TestRouter_MiddlewareComposedOnce(t *testing.T) {
+	var calls int64
+	router := NewRouter()
+	router.Use(http.MethodGet, MiddlewareFunc(func(handler Handler) Handler {
+		return func(ctx *Context) error {
+			atomic.AddInt64(&calls, 1)
+			return handler(ctx)
+		}
+	}))
+	router.Get("/users/:id", func(ctx *Context) error {
+		ctx.String("user")
+		return nil
+	})
+
+	handler := RouterHandler(router)
+	const requests = 50
+
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+			if rec.Body.String() != "user" {
+				t.Errorf("unexpected body: %q", rec.Body.String())
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != requests {
+		t.Errorf("expected middleware to run %d times, got %d", requests, calls)
+	}
+}
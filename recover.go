@@ -0,0 +1,77 @@
+package pulse
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+)
+
+// RecoverConfig configures RecoverMiddleware.
+type RecoverConfig struct {
+	// Log is called with the recovered panic and the stack trace of the
+	// goroutine, it defaults to printing them with the standard logger
+	Log func(ctx *Context, err error, stack []byte)
+
+	// StackSize is the maximum size of the captured stack trace, it
+	// defaults to DefaultStackSize
+	StackSize int
+
+	// StackAll captures the stack traces of all goroutines
+	StackAll bool
+
+	// RepanicAbort panics again with http.ErrAbortHandler instead of
+	// recovering it, so that net/http aborts the response as intended
+	RepanicAbort bool
+}
+
+// DefaultStackSize is the default size of the stack trace captured by
+// RecoverMiddleware.
+const DefaultStackSize = 4 << 10
+
+// RecoverMiddleware recovers from panics in the rest of the chain. The panic
+// is logged with its stack trace and returned as a 500 HTTPError wrapping it,
+// so that the error handler of the app responds to the client.
+func RecoverMiddleware(config ...RecoverConfig) MiddlewareFunc {
+	cfg := RecoverConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Log == nil {
+		cfg.Log = func(ctx *Context, err error, stack []byte) {
+			log.Printf("%v\n%s", err, stack)
+		}
+	}
+	if cfg.StackSize <= 0 {
+		cfg.StackSize = DefaultStackSize
+	}
+
+	return func(handler Handler) Handler {
+		return func(ctx *Context) (err error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				if r == http.ErrAbortHandler && cfg.RepanicAbort {
+					panic(r)
+				}
+
+				panicErr, ok := r.(error)
+				if ok {
+					panicErr = fmt.Errorf("panic: %w", panicErr)
+				} else {
+					panicErr = fmt.Errorf("panic: %v", r)
+				}
+
+				stack := make([]byte, cfg.StackSize)
+				stack = stack[:runtime.Stack(stack, cfg.StackAll)]
+				cfg.Log(ctx, panicErr, stack)
+
+				err = NewHTTPError(http.StatusInternalServerError).WithInternal(panicErr)
+			}()
+
+			return handler(ctx)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package pulse
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	var logged error
+	var stack []byte
+
+	var handled error
+	app := New(Config{
+		ErrorHandler: func(ctx *Context, err error) {
+			handled = err
+			DefaultErrorHandler(ctx, err)
+		},
+	})
+	app.Router.Use("*", RecoverMiddleware(RecoverConfig{
+		Log: func(ctx *Context, err error, s []byte) {
+			logged, stack = err, s
+		},
+	}))
+
+	cause := errors.New("nil map")
+	app.Router.Get("/error", func(ctx *Context) error {
+		panic(cause)
+	})
+	app.Router.Get("/value", func(ctx *Context) error {
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/error", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("unexpected status code: got %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if !errors.Is(handled, cause) {
+		t.Errorf("expected error handler to receive the panic, got %v", handled)
+	}
+	if !errors.Is(logged, cause) {
+		t.Errorf("expected the panic to be logged, got %v", logged)
+	}
+	if !strings.Contains(string(stack), "recover_test.go") {
+		t.Errorf("expected stack trace to contain the panicking handler, got %s", stack)
+	}
+
+	rec = httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/value", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("unexpected status code: got %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if logged == nil || logged.Error() != "panic: boom" {
+		t.Errorf("unexpected logged error: %v", logged)
+	}
+}
+
+func TestRecoverMiddleware_RepanicAbort(t *testing.T) {
+	router := NewRouter()
+	router.Use("*", RecoverMiddleware(RecoverConfig{
+		Log:          func(ctx *Context, err error, stack []byte) {},
+		RepanicAbort: true,
+	}))
+	router.Get("/", func(ctx *Context) error {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("expected http.ErrAbortHandler to be panicked again, got %v", r)
+		}
+	}()
+
+	RouterHandler(router).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
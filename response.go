@@ -0,0 +1,205 @@
+package pulse
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter is the http.ResponseWriter given to handlers through
+// Context.ResponseWriter. It records the status code and size of the response
+// so that middlewares can inspect them once the handlers return.
+//
+// It implements http.Flusher, http.Hijacker and http.Pusher only when the
+// underlying writer does, so that they can be detected with type assertions.
+type ResponseWriter interface {
+	http.ResponseWriter
+
+	// Status returns the status code of the response, which is
+	// http.StatusOK until WriteHeader is called.
+	Status() int
+
+	// Size returns the number of bytes of the body written so far.
+	Size() int
+
+	// Written reports whether the status code and headers were sent, or the
+	// connection was hijacked.
+	Written() bool
+
+	// Unwrap returns the underlying http.ResponseWriter.
+	Unwrap() http.ResponseWriter
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status  int
+	size    int
+	written bool
+}
+
+func (w *responseWriter) reset(rw http.ResponseWriter) {
+	w.ResponseWriter = rw
+	w.status = http.StatusOK
+	w.size = 0
+	w.written = false
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.written {
+		return
+	}
+	// Informational responses are sent ahead of the final one.
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	w.written = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.written = true
+	n, err := w.ResponseWriter.Write(p)
+	w.size += n
+	return n, err
+}
+
+// ReadFrom lets io.Copy use the io.ReaderFrom of the underlying writer, such
+// as the sendfile support of net/http.
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.written = true
+	var n int64
+	var err error
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(w.ResponseWriter, r)
+	}
+	w.size += int(n)
+	return n, err
+}
+
+func (w *responseWriter) flush() {
+	w.written = true
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (w *responseWriter) hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := w.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil {
+		w.written = true
+	}
+	return conn, rw, err
+}
+
+func (w *responseWriter) push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.written
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// wrap returns w as a ResponseWriter implementing the same optional
+// interfaces among http.Flusher, http.Hijacker and http.Pusher as the
+// underlying writer. The wrappers hold a single pointer, so that converting
+// them to an interface does not allocate.
+func (w *responseWriter) wrap() ResponseWriter {
+	_, flusher := w.ResponseWriter.(http.Flusher)
+	_, hijacker := w.ResponseWriter.(http.Hijacker)
+	_, pusher := w.ResponseWriter.(http.Pusher)
+
+	switch {
+	case flusher && hijacker && pusher:
+		return flushHijackPushWriter{w}
+	case flusher && hijacker:
+		return flushHijackWriter{w}
+	case flusher && pusher:
+		return flushPushWriter{w}
+	case hijacker && pusher:
+		return hijackPushWriter{w}
+	case flusher:
+		return flushWriter{w}
+	case hijacker:
+		return hijackWriter{w}
+	case pusher:
+		return pushWriter{w}
+	}
+	return w
+}
+
+type flushWriter struct{ *responseWriter }
+
+func (w flushWriter) Flush() {
+	w.flush()
+}
+
+type hijackWriter struct{ *responseWriter }
+
+func (w hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.hijack()
+}
+
+type pushWriter struct{ *responseWriter }
+
+func (w pushWriter) Push(target string, opts *http.PushOptions) error {
+	return w.push(target, opts)
+}
+
+type flushHijackWriter struct{ *responseWriter }
+
+func (w flushHijackWriter) Flush() {
+	w.flush()
+}
+
+func (w flushHijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.hijack()
+}
+
+type flushPushWriter struct{ *responseWriter }
+
+func (w flushPushWriter) Flush() {
+	w.flush()
+}
+
+func (w flushPushWriter) Push(target string, opts *http.PushOptions) error {
+	return w.push(target, opts)
+}
+
+type hijackPushWriter struct{ *responseWriter }
+
+func (w hijackPushWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.hijack()
+}
+
+func (w hijackPushWriter) Push(target string, opts *http.PushOptions) error {
+	return w.push(target, opts)
+}
+
+type flushHijackPushWriter struct{ *responseWriter }
+
+func (w flushHijackPushWriter) Flush() {
+	w.flush()
+}
+
+func (w flushHijackPushWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.hijack()
+}
+
+func (w flushHijackPushWriter) Push(target string, opts *http.PushOptions) error {
+	return w.push(target, opts)
+}
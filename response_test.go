@@ -0,0 +1,188 @@
+package pulse
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ctx := NewContext(rec, nil)
+	w := ctx.Writer()
+
+	if w.Status() != http.StatusOK || w.Size() != 0 || w.Written() {
+		t.Errorf("unexpected initial state: status %d, size %d, written %v", w.Status(), w.Size(), w.Written())
+	}
+
+	ctx.Status(http.StatusCreated)
+	ctx.Status(http.StatusAccepted)
+	ctx.String("hello")
+
+	if w.Status() != http.StatusCreated {
+		t.Errorf("unexpected status: got %d, want %d", w.Status(), http.StatusCreated)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("expected only the first status to be sent, got %d", rec.Code)
+	}
+	if w.Size() != 5 || !w.Written() {
+		t.Errorf("unexpected state after write: size %d, written %v", w.Size(), w.Written())
+	}
+	if w.Unwrap() != rec {
+		t.Errorf("expected Unwrap to return the underlying writer")
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		t.Fatalf("expected the writer of a recorder to be an http.Flusher")
+	}
+	flusher.Flush()
+	if !rec.Flushed {
+		t.Errorf("expected Flush to be forwarded")
+	}
+	if ctx.ResponseWriter != w {
+		t.Errorf("expected Context.ResponseWriter to be the tracking writer")
+	}
+}
+
+func TestResponseWriter_Informational(t *testing.T) {
+	ctx := NewContext(&discardResponseWriter{header: http.Header{}}, nil)
+	w := ctx.Writer()
+
+	ctx.Status(http.StatusEarlyHints)
+	if w.Written() || w.Status() != http.StatusOK {
+		t.Errorf("expected informational status not to be recorded, got status %d, written %v", w.Status(), w.Written())
+	}
+}
+
+func TestResponseWriter_Unsupported(t *testing.T) {
+	ctx := NewContext(&discardResponseWriter{header: http.Header{}}, nil)
+	w := ctx.Writer()
+
+	if _, ok := w.(http.Flusher); ok {
+		t.Errorf("expected the writer not to be an http.Flusher")
+	}
+	if _, ok := w.(http.Hijacker); ok {
+		t.Errorf("expected the writer not to be an http.Hijacker")
+	}
+	if _, ok := w.(http.Pusher); ok {
+		t.Errorf("expected the writer not to be an http.Pusher")
+	}
+}
+
+// capabilityWriter is an http.ResponseWriter that the types below extend
+// with one of the optional interfaces.
+type capabilityWriter struct {
+	discardResponseWriter
+}
+
+type (
+	flushCapability  struct{ *capabilityWriter }
+	hijackCapability struct{ *capabilityWriter }
+	pushCapability   struct{ *capabilityWriter }
+)
+
+func (flushCapability) Flush() {}
+
+func (hijackCapability) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("not a connection")
+}
+
+func (pushCapability) Push(string, *http.PushOptions) error {
+	return nil
+}
+
+func TestResponseWriter_Capabilities(t *testing.T) {
+	tests := []struct {
+		name                      string
+		writer                    http.ResponseWriter
+		flusher, hijacker, pusher bool
+	}{
+		{"none", &discardResponseWriter{header: http.Header{}}, false, false, false},
+		{"flusher", flushCapability{&capabilityWriter{}}, true, false, false},
+		{"hijacker", hijackCapability{&capabilityWriter{}}, false, true, false},
+		{"pusher", pushCapability{&capabilityWriter{}}, false, false, true},
+		{"recorder", httptest.NewRecorder(), true, false, false},
+	}
+	for _, tt := range tests {
+		ctx := NewContext(tt.writer, nil)
+		_, flusher := ctx.ResponseWriter.(http.Flusher)
+		_, hijacker := ctx.ResponseWriter.(http.Hijacker)
+		_, pusher := ctx.ResponseWriter.(http.Pusher)
+		if flusher != tt.flusher || hijacker != tt.hijacker || pusher != tt.pusher {
+			t.Errorf("%s: got flusher %v, hijacker %v, pusher %v", tt.name, flusher, hijacker, pusher)
+		}
+	}
+
+	// A failed hijack leaves the response writable.
+	ctx := NewContext(hijackCapability{&capabilityWriter{discardResponseWriter{header: http.Header{}}}}, nil)
+	if _, _, err := ctx.ResponseWriter.(http.Hijacker).Hijack(); err == nil {
+		t.Fatalf("expected the hijack to fail")
+	}
+	if ctx.Writer().Written() {
+		t.Errorf("expected a failed hijack not to mark the response as written")
+	}
+}
+
+func TestResponseWriter_Hijack(t *testing.T) {
+	router := NewRouter()
+	router.Get("/", func(ctx *Context) error {
+		conn, rw, err := ctx.Writer().(http.Hijacker).Hijack()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if !ctx.Writer().Written() {
+			t.Errorf("expected a hijacked response to be written")
+		}
+		_, _ = rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		return rw.Flush()
+	})
+
+	server := httptest.NewServer(RouterHandler(router))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, _ = conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "hijacked" {
+		t.Errorf("unexpected body: %q", body)
+	}
+}
+
+func TestDefaultErrorHandler_Written(t *testing.T) {
+	router := NewRouter()
+	router.Get("/", func(ctx *Context) error {
+		ctx.String("partial")
+		return errors.New("failed after writing")
+	})
+
+	rec := httptest.NewRecorder()
+	RouterHandler(router).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("unexpected status code: got %d, want %d", rec.Code, http.StatusOK)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "Internal Server Error") {
+		t.Errorf("expected error handler not to write after the response started, got %q", body)
+	}
+}
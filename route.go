@@ -1,7 +1,11 @@
 package pulse
 
 import (
+	"fmt"
+	"github.com/gopulse/pulse/constants"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
 type Route struct {
@@ -9,49 +13,142 @@ type Route struct {
 	Path       string
 	Handlers   []Handler
 	ParamNames []string
+
+	name        string
+	optional    bool
+	router      *Router
+	group       *Group
+	middlewares []Middleware
+	chain       []Handler
+}
+
+// Name sets the name used to build URLs for the route with Router.URL.
+func (r *Route) Name(name string) *Route {
+	if existing, ok := r.router.names[name]; ok && existing != r {
+		panic(fmt.Sprintf("pulse: route name %q is already used by route %q", name, existing.Path))
+	}
+	if r.name != "" {
+		delete(r.router.names, r.name)
+	}
+	r.name = name
+	r.router.names[name] = r
+	return r
+}
+
+// URL builds the path of the route registered under name. Params are given as
+// name and value pairs, such as URL("user.show", "id", 42), and are escaped
+// before being substituted. The wildcard is filled with the "*" param, which
+// is required like the other params, and optional params may be left out.
+func (r *Router) URL(name string, params ...any) (string, error) {
+	route, ok := r.names[name]
+	if !ok {
+		return "", fmt.Errorf("no route named %q", name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("odd number of params for route %q", name)
+	}
+
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		key, ok := params[i].(string)
+		if !ok {
+			return "", fmt.Errorf("param name %v of route %q is not a string", params[i], name)
+		}
+		values[key] = fmt.Sprint(params[i+1])
+	}
+
+	parts := strings.Split(route.Path, "/")
+	end, missing := len(parts), ""
+	for i, part := range parts {
+		switch {
+		case part == constants.WildcardSign:
+			value, ok := values[constants.WildcardSign]
+			if !ok {
+				return "", fmt.Errorf("missing param %q for route %q", constants.WildcardSign, name)
+			}
+			delete(values, constants.WildcardSign)
+			segments := strings.Split(value, "/")
+			for j, segment := range segments {
+				segments[j] = url.PathEscape(segment)
+			}
+			parts[i] = strings.Join(segments, "/")
+		case strings.HasPrefix(part, constants.ParamSign):
+			param, spec, optional := parseParam(part)
+			value, ok := values[param]
+			if !ok {
+				if !optional {
+					return "", fmt.Errorf("missing param %q for route %q", param, name)
+				}
+				// Optional params are trailing, so the path ends at the
+				// first one left out.
+				if missing == "" {
+					missing, end = param, i
+				}
+				continue
+			}
+			if missing != "" {
+				return "", fmt.Errorf("missing param %q for route %q", missing, name)
+			}
+			delete(values, param)
+			if spec != "" && !r.constraint(spec)(value) {
+				return "", fmt.Errorf("param %q of route %q does not satisfy constraint %q: %q", param, name, spec, value)
+			}
+			parts[i] = url.PathEscape(value)
+		}
+	}
+
+	for param := range values {
+		return "", fmt.Errorf("unknown param %q for route %q", param, name)
+	}
+
+	path := strings.Join(parts[:end], "/")
+	if path == "" {
+		path = "/"
+	}
+	return path, nil
 }
 
 // Get adds the route to the router with the GET method
-func (r *Router) Get(path string, handlers ...Handler) {
-	r.Add(http.MethodGet, path, handlers...)
+func (r *Router) Get(path string, handlers ...Handler) *Route {
+	return r.Add(http.MethodGet, path, handlers...)
 }
 
 // Post adds the route to the router with the POST method
-func (r *Router) Post(path string, handlers ...Handler) {
-	r.Add(http.MethodPost, path, handlers...)
+func (r *Router) Post(path string, handlers ...Handler) *Route {
+	return r.Add(http.MethodPost, path, handlers...)
 }
 
 // Put adds the route to the router with the PUT method
-func (r *Router) Put(path string, handlers ...Handler) {
-	r.Add(http.MethodPut, path, handlers...)
+func (r *Router) Put(path string, handlers ...Handler) *Route {
+	return r.Add(http.MethodPut, path, handlers...)
 }
 
 // Delete adds the route to the router with the DELETE method
-func (r *Router) Delete(path string, handlers ...Handler) {
-	r.Add(http.MethodDelete, path, handlers...)
+func (r *Router) Delete(path string, handlers ...Handler) *Route {
+	return r.Add(http.MethodDelete, path, handlers...)
 }
 
 // Patch adds the route to the router with the PATCH method
-func (r *Router) Patch(path string, handlers ...Handler) {
-	r.Add(http.MethodPatch, path, handlers...)
+func (r *Router) Patch(path string, handlers ...Handler) *Route {
+	return r.Add(http.MethodPatch, path, handlers...)
 }
 
 // Head adds the route to the router with the HEAD method
-func (r *Router) Head(path string, handlers ...Handler) {
-	r.Add(http.MethodHead, path, handlers...)
+func (r *Router) Head(path string, handlers ...Handler) *Route {
+	return r.Add(http.MethodHead, path, handlers...)
 }
 
 // Options adds the route to the router with the OPTIONS method
-func (r *Router) Options(path string, handlers ...Handler) {
-	r.Add(http.MethodOptions, path, handlers...)
+func (r *Router) Options(path string, handlers ...Handler) *Route {
+	return r.Add(http.MethodOptions, path, handlers...)
 }
 
 // Connect adds the route to the router with the CONNECT method
-func (r *Router) Connect(path string, handlers ...Handler) {
-	r.Add(http.MethodConnect, path, handlers...)
+func (r *Router) Connect(path string, handlers ...Handler) *Route {
+	return r.Add(http.MethodConnect, path, handlers...)
 }
 
 // Trace adds the route to the router with the TRACE method
-func (r *Router) Trace(path string, handlers ...Handler) {
-	r.Add(http.MethodTrace, path, handlers...)
+func (r *Router) Trace(path string, handlers ...Handler) *Route {
+	return r.Add(http.MethodTrace, path, handlers...)
 }
@@ -100,3 +100,85 @@ func TestRoute_Trace(t *testing.T) {
 		return nil
 	})
 }
+
+func TestRoute_Name(t *testing.T) {
+	router := NewRouter()
+
+	route := router.Get("/users/:id", func(ctx *Context) error {
+		return nil
+	}).Name("user.show")
+
+	if router.names["user.show"] != route {
+		t.Errorf("expected route to be registered as %q", "user.show")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a duplicate route name to panic")
+		}
+	}()
+	router.Get("/posts/:id", func(ctx *Context) error {
+		return nil
+	}).Name("user.show")
+}
+
+func TestRouter_URL(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx *Context) error { return nil }
+	router.Get("/", handler).Name("home")
+	router.Get("/users/:id<int>", handler).Name("user.show")
+	router.Get("/users/:id/posts/:slug", handler).Name("user.post")
+	router.Get("/files/:name?/:ext?", handler).Name("files")
+	router.Get("/assets/*", handler).Name("assets")
+
+	api := &Group{Prefix: "/api", Router: router}
+	api.GET("/status", handler).Name("api.status")
+
+	tests := []struct {
+		name   string
+		params []any
+		url    string
+	}{
+		{"home", nil, "/"},
+		{"user.show", []any{"id", 42}, "/users/42"},
+		{"user.post", []any{"id", 1, "slug", "hello world/again"}, "/users/1/posts/hello%20world%2Fagain"},
+		{"files", nil, "/files"},
+		{"files", []any{"name", "report"}, "/files/report"},
+		{"files", []any{"name", "report", "ext", "pdf"}, "/files/report/pdf"},
+		{"assets", []any{"*", "css/my app.css"}, "/assets/css/my%20app.css"},
+		{"assets", []any{"*", ""}, "/assets/"},
+		{"api.status", nil, "/api/status"},
+	}
+
+	for _, tt := range tests {
+		url, err := router.URL(tt.name, tt.params...)
+		if err != nil {
+			t.Errorf("URL(%q, %v): unexpected error: %v", tt.name, tt.params, err)
+			continue
+		}
+		if url != tt.url {
+			t.Errorf("URL(%q, %v): expected %q, got %q", tt.name, tt.params, tt.url, url)
+		}
+	}
+
+	errors := []struct {
+		name   string
+		params []any
+	}{
+		{"unknown", nil},
+		{"user.show", nil},
+		{"user.show", []any{"id"}},
+		{"user.show", []any{"id", "abc"}},
+		{"user.show", []any{"id", 1, "name", "john"}},
+		{"user.post", []any{"id", 1}},
+		{"files", []any{"ext", "pdf"}},
+		{"assets", nil},
+		{"home", []any{1, 2}},
+	}
+
+	for _, tt := range errors {
+		if url, err := router.URL(tt.name, tt.params...); err == nil {
+			t.Errorf("URL(%q, %v): expected an error, got %q", tt.name, tt.params, url)
+		}
+	}
+}
@@ -1,33 +1,46 @@
 package pulse
 
 import (
+	"fmt"
 	"github.com/gopulse/pulse/constants"
 	"net/http"
+	"sort"
 	"strings"
-	"time"
+	"sync"
 )
 
 type Handler func(ctx *Context) error
 type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
 
 type Router struct {
-	routes          map[string][]*Route
-	notFoundHandler Handler
-	middlewares     map[string][]Middleware
-}
-
-type Static struct {
-	Root          string
-	Compress      bool
-	ByteRange     bool
-	IndexName     string
-	CacheDuration time.Duration
+	routes                  map[string][]*Route
+	trees                   map[string]*node
+	notFoundHandler         Handler
+	methodNotAllowedHandler Handler
+	middlewares             map[string][]Middleware
+	constraints             map[string]Constraint
+	names                   map[string]*Route
+	pool                    sync.Pool
+	errorHandler            ErrorHandler
+	app                     *Pulse
+	pending                 []*Route
 }
 
 func NewRouter() *Router {
 	router := &Router{
 		routes:      make(map[string][]*Route),
+		trees:       make(map[string]*node),
 		middlewares: make(map[string][]Middleware),
+		constraints: make(map[string]Constraint),
+		names:       make(map[string]*Route),
+	}
+
+	router.errorHandler = DefaultErrorHandler
+
+	router.pool.New = func() interface{} {
+		c := NewContext(nil, nil)
+		c.router = router
+		return c
 	}
 
 	router.notFoundHandler = func(ctx *Context) error {
@@ -35,144 +48,270 @@ func NewRouter() *Router {
 		return nil
 	}
 
+	router.methodNotAllowedHandler = func(ctx *Context) error {
+		http.Error(ctx.ResponseWriter, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return nil
+	}
+
 	return router
 }
 
-func (r *Router) Add(method, path string, handlers ...Handler) {
+// NotFound sets the handler called when no route matches the request path.
+func (r *Router) NotFound(handler Handler) {
+	r.notFoundHandler = handler
+}
+
+// MethodNotAllowed sets the handler called when the path matches a route
+// registered for other methods only. The Allow header is already set when
+// the handler runs.
+func (r *Router) MethodNotAllowed(handler Handler) {
+	r.methodNotAllowedHandler = handler
+}
+
+// Add registers a route for the method and path. When several routes are
+// registered for the same method and path, the first one is served, but a
+// route with optional params conflicting with another route panics.
+func (r *Router) Add(method, path string, handlers ...Handler) *Route {
+	return r.add(method, path, nil, handlers)
+}
+
+// add registers a route of the group, which is nil for routes added to the
+// router directly. The route is complete when the route hooks of the app see
+// it, and is not served when a hook rejects it.
+func (r *Router) add(method, path string, group *Group, handlers []Handler) *Route {
 	route := &Route{
+		Method:   method,
 		Path:     path,
 		Handlers: handlers,
+		router:   r,
+		group:    group,
 	}
 
 	parts := strings.Split(path, "/")
 	for _, part := range parts {
 		if strings.HasPrefix(part, constants.ParamSign) {
-			route.ParamNames = append(route.ParamNames, strings.TrimPrefix(part, constants.ParamSign))
+			name, _, _ := parseParam(part)
+			route.ParamNames = append(route.ParamNames, name)
 		}
 	}
 	route.Path = strings.Join(parts, "/")
+	r.compose(route)
 
-	r.routes[method] = append(r.routes[method], route)
-}
+	if r.app != nil && !r.app.runRouteHooks(route) {
+		return route
+	}
 
-func (r *Router) Find(method, path string) []Handler {
-	routes, ok := r.routes[method]
+	tree, ok := r.trees[method]
 	if !ok {
-		return nil
+		tree = &node{}
+		r.trees[method] = tree
 	}
-
-	for _, route := range routes {
-		if matches, params := route.match(path); matches {
-			c := NewContext(nil, nil)
-			c.Params = params
-			return r.applyMiddleware(route.Handlers, method)
+	variants := optionalVariants(route.Path)
+	route.optional = len(variants) > 1
+	for _, variant := range variants {
+		// The first route registered for a path wins, but an optional param
+		// silently shadowing another route is almost always a mistake.
+		if existing := tree.insert(variant, route, r.constraint); existing != nil && (route.optional || existing.optional) {
+			panic(fmt.Sprintf("pulse: route %q conflicts with existing route %q", route.Path, existing.Path))
 		}
 	}
 
-	return nil
+	r.routes[method] = append(r.routes[method], route)
+	if r.app == nil {
+		r.pending = append(r.pending, route)
+	}
+
+	return route
 }
 
-func (r *Router) applyMiddleware(handlers []Handler, method string) []Handler {
-	for i := len(r.middlewares[method]) - 1; i >= 0; i-- {
-		middleware := r.middlewares[method][i]
-		for j := len(handlers) - 1; j >= 0; j-- {
-			handler := handlers[j]
-			handlers[j] = func(ctx *Context) error {
-				return middleware.Handle(ctx, handler)
-			}
+// remove unregisters the route, rebuilding the tree of its method from the
+// remaining routes.
+func (r *Router) remove(route *Route) {
+	routes := r.routes[route.Method]
+	for i, existing := range routes {
+		if existing == route {
+			routes = append(routes[:i:i], routes[i+1:]...)
+			break
 		}
 	}
-	return handlers
-}
-
-func RouterHandler(router *Router) http.HandlerFunc {
-	return func(w http.ResponseWriter, req *http.Request) {
-		path := req.URL.Path
-		method := req.Method
-		handlers := router.Find(method, path)
+	if r.names[route.name] == route {
+		delete(r.names, route.name)
+	}
+	if len(routes) == 0 {
+		delete(r.routes, route.Method)
+		delete(r.trees, route.Method)
+		return
+	}
 
-		c := NewContext(w, req)
-		for _, h := range handlers {
-			err := h(c)
-			if err != nil {
-				break
-			}
+	tree := &node{}
+	for _, existing := range routes {
+		for _, variant := range optionalVariants(existing.Path) {
+			tree.insert(variant, existing, r.constraint)
 		}
 	}
+	r.routes[route.Method] = routes
+	r.trees[route.Method] = tree
 }
 
-func (r *Route) match(path string) (bool, map[string]string) {
+// optionalVariants expands a path with optional params into every path it
+// matches, from the longest to the shortest. Optional params may only be
+// followed by other optional params, so "/files/:name?/:ext?" expands to
+// "/files/:name/:ext", "/files/:name" and "/files".
+func optionalVariants(path string) []string {
 	parts := strings.Split(path, "/")
-	routeParts := strings.Split(r.Path, "/")
 
-	if strings.HasSuffix(path, "/") {
-		parts = parts[:len(parts)-1]
+	first := -1
+	for i, part := range parts {
+		if strings.HasPrefix(part, constants.ParamSign) && strings.HasSuffix(part, constants.OptionalSign) {
+			parts[i] = strings.TrimSuffix(part, constants.OptionalSign)
+			if first < 0 {
+				first = i
+			}
+		} else if first >= 0 && part != "" {
+			panic(fmt.Sprintf("pulse: optional param %q must not be followed by required segments in route %q", parts[first], path))
+		}
 	}
 
-	if len(parts) != len(routeParts) {
-		return false, nil
+	if first < 0 {
+		return []string{path}
 	}
 
-	params := make(map[string]string)
-	for i, part := range routeParts {
-		if strings.HasPrefix(part, constants.ParamSign) {
-			paramName := strings.TrimPrefix(part, constants.ParamSign)
-			params[paramName] = parts[i]
-		} else if part == constants.WildcardSign {
-			return true, params
-		} else if part != parts[i] {
-			return false, nil
+	variants := make([]string, 0, len(parts)-first+1)
+	for i := len(parts); i >= first; i-- {
+		variant := strings.Join(parts[:i], "/")
+		if variant == "" {
+			variant = "/"
 		}
+		variants = append(variants, variant)
 	}
-
-	return true, params
+	return variants
 }
 
-func (options *Static) notFoundHandler(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusNotFound)
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	_, err := w.Write([]byte("404 Not Found"))
-	if err != nil {
-		return
+// Find returns the route matching the method and path along with the values
+// of its params, keyed by name. Wildcard values are stored under "*" and
+// optional params that are absent from the path are left out. The params are
+// nil when the route has none.
+func (r *Router) Find(method, path string) (*Route, map[string]string) {
+	n, values := r.lookup(method, path, nil)
+	if n == nil {
+		return nil, nil
 	}
+
+	var params map[string]string
+	if len(values) > 0 {
+		params = make(map[string]string, len(values))
+		for i, value := range values {
+			params[n.paramNames[i]] = value
+		}
+	}
+	return n.route, params
 }
 
-func (options *Static) PathRewrite(r *http.Request) []byte {
-	path := r.URL.Path
+// lookup returns the tree node matching the path for the given method along
+// with the captured param values. A single trailing slash is ignored when the
+// path does not match as is. Static and param routes matching the path without
+// its trailing slash win over wildcards, so a catch-all such as a static file
+// prefix does not take the trailing-slash URLs of the other routes.
+func (r *Router) lookup(method, path string, values []string) (*node, []string) {
+	tree, ok := r.trees[method]
+	if !ok {
+		return nil, values
+	}
 
-	if len(path) > 1 && path[len(path)-1] == '/' {
-		path = path[:len(path)-1]
+	if len(path) < 2 || path[len(path)-1] != '/' {
+		return tree.search(path, values)
 	}
 
-	// Remove the last part of the path
-	parts := strings.Split(path, "/")
-	if len(parts) > 1 {
-		parts = parts[:len(parts)-1]
+	trimmed := path[:len(path)-1]
+	if n, v := tree.match(path, values, false); n != nil {
+		return n, v
 	}
-	path = strings.Join(parts, "/")
+	if n, v := tree.match(trimmed, values[:0], false); n != nil {
+		return n, v
+	}
+	if n, v := tree.search(path, values[:0]); n != nil {
+		return n, v
+	}
+	return tree.search(trimmed, values[:0])
+}
+
+// allowed returns the sorted list of methods that have a route matching path.
+func (r *Router) allowed(path string) []string {
+	var methods []string
+	for method := range r.trees {
+		if n, _ := r.lookup(method, path, nil); n != nil {
+			methods = append(methods, method)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
 
-	if options.IndexName != "" {
-		// Append the index file name to the path
-		path += "/"
-		path += options.IndexName
+// chain returns the handlers of a chain made of the middlewares followed by
+// the handlers.
+func chain(handlers []Handler, middlewares []Middleware) []Handler {
+	c := make([]Handler, 0, len(middlewares)+len(handlers))
+	for _, middleware := range middlewares {
+		c = append(c, middlewareHandler(middleware))
 	}
+	return append(c, handlers...)
+}
 
-	return []byte(path)
+// compose caches the chain of middlewares and handlers of the route so that
+// requests do not have to build it again.
+func (r *Router) compose(route *Route) {
+	route.chain = chain(route.Handlers, r.routeMiddlewares(route))
 }
 
-func (r *Router) Static(prefix, root string, options *Static) {
-	if options == nil {
-		options = &Static{}
+// rebuild composes the chain of every route again after a change to the
+// middlewares shared between routes.
+func (r *Router) rebuild() {
+	for _, routes := range r.routes {
+		for _, route := range routes {
+			r.compose(route)
+		}
 	}
-	if options.Root == "" {
-		options.Root = root
+}
+
+func RouterHandler(router *Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		path := req.URL.Path
+		method := req.Method
+		c := router.acquireContext(w, req)
+
+		n, values := router.lookup(method, path, c.paramValues[:0])
+		c.paramValues = values
+		if n != nil {
+			c.route = n.route
+			c.handlers = n.route.chain
+			for i, value := range values {
+				c.Params[n.paramNames[i]] = value
+			}
+		} else {
+			fallback := router.notFoundHandler
+			if allowed := router.allowed(path); len(allowed) > 0 {
+				c.SetResponseHeader("Allow", strings.Join(allowed, ", "))
+				fallback = router.methodNotAllowedHandler
+			}
+			c.handlers = chain([]Handler{fallback}, router.routerMiddlewares(method))
+		}
+
+		if err := c.Next(); err != nil {
+			c.handleError(err)
+		}
+
+		router.releaseContext(c)
 	}
-	fs := http.FileServer(http.Dir(options.Root))
+}
 
-	handler := http.StripPrefix(prefix, fs)
+func (r *Router) acquireContext(w http.ResponseWriter, req *http.Request) *Context {
+	c := r.pool.Get().(*Context)
+	c.setResponseWriter(w)
+	c.Request = req
+	return c
+}
 
-	r.Get(prefix, func(ctx *Context) error {
-		handler.ServeHTTP(ctx.ResponseWriter, ctx.Request)
-		return nil
-	})
+func (r *Router) releaseContext(c *Context) {
+	c.Reset()
+	r.pool.Put(c)
 }
@@ -1,9 +1,12 @@
 package pulse
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -96,3 +99,403 @@ func TestStatic_PathRewrite(t *testing.T) {
 		t.Errorf("unexpected path rewrite: got %q, want %q", actualRewritten, expectedRewritten)
 	}
 }
+
+func TestRouter_FindTrailingSlash(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users", func(ctx *Context) error {
+		return nil
+	})
+
+	if route, _ := router.Find(http.MethodGet, "/users/"); route == nil || route.Path != "/users" {
+		t.Errorf("expected /users/ to match /users, got %v", route)
+	}
+	if route, _ := router.Find(http.MethodPost, "/users"); route != nil {
+		t.Errorf("expected no route for unregistered method, got %q", route.Path)
+	}
+}
+
+func TestRouter_FindTrailingSlashWildcard(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx *Context) error { return nil }
+	router.Get("/*", handler)
+	router.Get("/api/users", handler)
+	router.Get("/a/:id/x", handler)
+	router.Get("/a/*", handler)
+	router.Get("/files/*", handler)
+
+	tests := []struct {
+		path  string
+		route string
+		param string
+	}{
+		{"/api/users/", "/api/users", ""},
+		{"/a/b/x/", "/a/:id/x", "b"},
+		{"/a/b/y/", "/a/*", "b/y/"},
+		{"/files/", "/files/*", ""},
+		{"/other/", "/*", "other/"},
+	}
+	for _, tt := range tests {
+		route, params := router.Find(http.MethodGet, tt.path)
+		if route == nil || route.Path != tt.route {
+			t.Errorf("%s: expected route %q, got %v", tt.path, tt.route, route)
+			continue
+		}
+		if got := params["id"] + params["*"]; got != tt.param {
+			t.Errorf("%s: expected param %q, got %q", tt.path, tt.param, got)
+		}
+	}
+}
+
+// benchmarkRoutes registers n static and n param routes on a new router.
+func benchmarkRoutes(n int) (*Router, []*Route) {
+	router := NewRouter()
+	handler := func(ctx *Context) error { return nil }
+	for i := 0; i < n; i++ {
+		router.Get(fmt.Sprintf("/static/route%d/list", i), handler)
+		router.Get(fmt.Sprintf("/param/route%d/:id", i), handler)
+	}
+	return router, router.routes[http.MethodGet]
+}
+
+// linearFind mirrors the route matching used before the radix tree, which
+// scanned every route and split both paths for each of them.
+func linearFind(routes []*Route, path string) *Route {
+	for _, route := range routes {
+		parts := strings.Split(path, "/")
+		routeParts := strings.Split(route.Path, "/")
+		if len(parts) != len(routeParts) {
+			continue
+		}
+
+		params := make(map[string]string)
+		matched := true
+		for i, part := range routeParts {
+			if strings.HasPrefix(part, ":") {
+				params[part[1:]] = parts[i]
+			} else if part != parts[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return route
+		}
+	}
+	return nil
+}
+
+func BenchmarkRouter_FindStatic(b *testing.B) {
+	router, _ := benchmarkRoutes(250)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.Find(http.MethodGet, "/static/route249/list")
+	}
+}
+
+func BenchmarkRouter_FindParam(b *testing.B) {
+	router, _ := benchmarkRoutes(250)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.Find(http.MethodGet, "/param/route249/42")
+	}
+}
+
+func BenchmarkLinear_FindStatic(b *testing.B) {
+	_, routes := benchmarkRoutes(250)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		linearFind(routes, "/static/route249/list")
+	}
+}
+
+func BenchmarkLinear_FindParam(b *testing.B) {
+	_, routes := benchmarkRoutes(250)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		linearFind(routes, "/param/route249/42")
+	}
+}
+
+func TestRouterHandler_MethodNotAllowed(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx *Context) error { return nil }
+	router.Get("/users/:id", handler)
+	router.Put("/users/:id", handler)
+	router.Delete("/users/*", handler)
+
+	rec := httptest.NewRecorder()
+	RouterHandler(router).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users/1", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("unexpected status code: got %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if allow := rec.Header().Get("Allow"); allow != "DELETE, GET, PUT" {
+		t.Errorf("unexpected Allow header: got %q, want %q", allow, "DELETE, GET, PUT")
+	}
+}
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users", func(ctx *Context) error { return nil })
+	router.MethodNotAllowed(func(ctx *Context) error {
+		_, err := ctx.JSON(http.StatusMethodNotAllowed, map[string]string{"allow": ctx.GetResponseHeader("Allow")})
+		return err
+	})
+
+	rec := httptest.NewRecorder()
+	RouterHandler(router).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("unexpected status code: got %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if body := rec.Body.String(); body != `{"allow":"GET"}` {
+		t.Errorf("unexpected body: got %q", body)
+	}
+
+	rec = httptest.NewRecorder()
+	RouterHandler(router).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/posts", nil))
+	if rec.Code == http.StatusMethodNotAllowed {
+		t.Errorf("expected unknown path not to be reported as 405")
+	}
+}
+
+func TestRouterHandler_NotFound(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users", func(ctx *Context) error { return nil })
+
+	rec := httptest.NewRecorder()
+	RouterHandler(router).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/posts", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unexpected status code: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestRouter_NotFound(t *testing.T) {
+	router := NewRouter()
+	router.Use(http.MethodGet, CORSMiddleware())
+	router.NotFound(func(ctx *Context) error {
+		_, err := ctx.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+		return err
+	})
+
+	rec := httptest.NewRecorder()
+	RouterHandler(router).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/posts", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unexpected status code: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if body := rec.Body.String(); body != `{"error":"not found"}` {
+		t.Errorf("unexpected body: got %q", body)
+	}
+	if header := rec.Header().Get("Access-Control-Allow-Origin"); header != "*" {
+		t.Errorf("expected not found handler to run through middleware, got Access-Control-Allow-Origin %q", header)
+	}
+}
+
+func TestRouter_OptionalParams(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id?", func(ctx *Context) error { return nil })
+	router.Get("/files/:name?/:ext?", func(ctx *Context) error { return nil })
+
+	tests := []struct {
+		path   string
+		route  string
+		names  []string
+		values []string
+	}{
+		{"/users", "/users/:id?", nil, nil},
+		{"/users/", "/users/:id?", nil, nil},
+		{"/users/1", "/users/:id?", []string{"id"}, []string{"1"}},
+		{"/files", "/files/:name?/:ext?", nil, nil},
+		{"/files/report", "/files/:name?/:ext?", []string{"name"}, []string{"report"}},
+		{"/files/report/pdf", "/files/:name?/:ext?", []string{"name", "ext"}, []string{"report", "pdf"}},
+	}
+
+	for _, tt := range tests {
+		n, values := router.lookup(http.MethodGet, tt.path, nil)
+		if n == nil {
+			t.Errorf("%s: expected a match", tt.path)
+			continue
+		}
+		if n.route.Path != tt.route {
+			t.Errorf("%s: expected route %q, got %q", tt.path, tt.route, n.route.Path)
+		}
+		if !reflect.DeepEqual(n.paramNames, tt.names) || !reflect.DeepEqual(values, tt.values) {
+			t.Errorf("%s: expected params %v=%v, got %v=%v", tt.path, tt.names, tt.values, n.paramNames, values)
+		}
+	}
+
+	if n, _ := router.lookup(http.MethodGet, "/files/report/pdf/extra", nil); n != nil {
+		t.Errorf("expected no match for extra segments, got %q", n.route.Path)
+	}
+
+	route := router.routes[http.MethodGet][1]
+	if !reflect.DeepEqual(route.ParamNames, []string{"name", "ext"}) {
+		t.Errorf("unexpected param names: %v", route.ParamNames)
+	}
+}
+
+func TestRouter_OptionalParamsConflict(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+	}{
+		{"shadows static", []string{"/users", "/users/:id?"}},
+		{"shadowed by static", []string{"/users/:id?", "/users"}},
+		{"shadows param", []string{"/users/:name", "/users/:id?"}},
+		{"required after optional", []string{"/users/:id?/posts"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected registering %v to panic", tt.paths)
+				}
+			}()
+
+			router := NewRouter()
+			for _, path := range tt.paths {
+				router.Get(path, func(ctx *Context) error { return nil })
+			}
+		})
+	}
+}
+
+func TestRouter_DuplicateRoute(t *testing.T) {
+	router := NewRouter()
+	first := router.Get("/users/:id", func(ctx *Context) error { return nil })
+	router.Get("/users/:name", func(ctx *Context) error { return nil })
+	router.Static("/users", "./static", nil)
+
+	// The first route registered for a method and path wins.
+	if route, _ := router.Find(http.MethodGet, "/users/1"); route != first {
+		t.Errorf("expected the first route to match, got %v", route)
+	}
+}
+
+func TestRouter_Find(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx *Context) error { return nil }
+	router.Get("/users", handler)
+	router.Get("/users/:id/files/*", handler)
+	router.Get("/posts/:id?", handler)
+
+	tests := []struct {
+		path   string
+		route  string
+		params map[string]string
+	}{
+		{"/users", "/users", nil},
+		{"/users/1/files/a/b.txt", "/users/:id/files/*", map[string]string{"id": "1", "*": "a/b.txt"}},
+		{"/posts", "/posts/:id?", nil},
+		{"/posts/2", "/posts/:id?", map[string]string{"id": "2"}},
+	}
+
+	for _, tt := range tests {
+		route, params := router.Find(http.MethodGet, tt.path)
+		if route == nil || route.Path != tt.route {
+			t.Errorf("%s: expected route %q, got %v", tt.path, tt.route, route)
+			continue
+		}
+		if !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("%s: expected params %v, got %v", tt.path, tt.params, params)
+		}
+	}
+}
+
+func TestRouterHandler_Params(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:id/posts/:post", func(ctx *Context) error {
+		ctx.String(ctx.Param("id") + "/" + ctx.Param("post"))
+		return nil
+	})
+	router.Get("/files/:name?", func(ctx *Context) error {
+		ctx.String("name=" + ctx.Param("name"))
+		return nil
+	})
+	router.Get("/assets/*", func(ctx *Context) error {
+		ctx.String(ctx.Param("*"))
+		return nil
+	})
+
+	tests := []struct {
+		path string
+		body string
+	}{
+		{"/users/42/posts/7", "42/7"},
+		{"/files/report", "name=report"},
+		{"/files", "name="},
+		{"/assets/css/app.css", "css/app.css"},
+	}
+
+	handler := RouterHandler(router)
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if rec.Body.String() != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.path, tt.body, rec.Body.String())
+		}
+	}
+}
+
+// discardResponseWriter is a ResponseWriter that does not allocate, so that
+// benchmarks only measure the router.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
+
+func BenchmarkRouterHandler_Static(b *testing.B) {
+	router, _ := benchmarkRoutes(250)
+	handler := RouterHandler(router)
+	w := &discardResponseWriter{header: http.Header{}}
+	req := httptest.NewRequest(http.MethodGet, "/static/route249/list", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkRouterHandler_Param(b *testing.B) {
+	router, _ := benchmarkRoutes(250)
+	handler := RouterHandler(router)
+	w := &discardResponseWriter{header: http.Header{}}
+	req := httptest.NewRequest(http.MethodGet, "/param/route249/42", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler.ServeHTTP(w, req)
+	}
+}
+
+// BenchmarkRouterHandler_ParamUnpooled builds a new Context and params map
+// for each request the way RouterHandler did before contexts were pooled.
+func BenchmarkRouterHandler_ParamUnpooled(b *testing.B) {
+	router, _ := benchmarkRoutes(250)
+	w := &discardResponseWriter{header: http.Header{}}
+	req := httptest.NewRequest(http.MethodGet, "/param/route249/42", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		route, params := router.Find(req.Method, req.URL.Path)
+		c := NewContext(w, req)
+		c.Params = params
+		for _, h := range route.chain {
+			_ = h(c)
+		}
+	}
+}
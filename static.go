@@ -0,0 +1,443 @@
+package pulse
+
+import (
+	"compress/gzip"
+	"errors"
+	"github.com/gopulse/pulse/constants"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Static configures the files served by Router.Static and Router.StaticFS.
+//
+// Files with a precompressed sibling, such as app.js.br or app.js.gz for
+// app.js, are sent compressed to the clients accepting the encoding of the
+// sibling, brotli being preferred over gzip.
+type Static struct {
+	// Root is the directory of the files, it defaults to the root given to
+	// Router.Static and is not used by Router.StaticFS
+	Root string
+
+	// Compress gzips the text files, such as HTML, CSS and JavaScript, for
+	// the clients accepting it when they have no precompressed sibling
+	Compress bool
+
+	// ByteRange enables the Range and If-Range request headers, so that
+	// clients can download parts of the files
+	ByteRange bool
+
+	// IndexName is the file served for directories, it defaults to
+	// DefaultIndexName
+	IndexName string
+
+	// CacheDuration is how long clients may cache the files, through the
+	// Cache-Control and Expires headers. The files are not cached when zero.
+	CacheDuration time.Duration
+
+	// SPA serves the index file of the root for the missing files without
+	// an extension, so that a single-page app can route them on the client
+	// side. The index is then sent with no-cache headers.
+	SPA bool
+}
+
+// DefaultIndexName is the default file served for directories by Static.
+const DefaultIndexName = "index.html"
+
+func (options *Static) notFoundHandler(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	_, err := w.Write([]byte("404 Not Found"))
+	if err != nil {
+		return
+	}
+}
+
+// PathRewrite returns the path of the request with its last segment replaced
+// by IndexName.
+//
+// Deprecated: the static handler does not use PathRewrite. Index files are
+// served from IndexName and SPA fallbacks from Static.SPA.
+func (options *Static) PathRewrite(r *http.Request) []byte {
+	path := r.URL.Path
+
+	if len(path) > 1 && path[len(path)-1] == '/' {
+		path = path[:len(path)-1]
+	}
+
+	// Remove the last part of the path
+	parts := strings.Split(path, "/")
+	if len(parts) > 1 {
+		parts = parts[:len(parts)-1]
+	}
+	path = strings.Join(parts, "/")
+
+	if options.IndexName != "" {
+		// Append the index file name to the path
+		path += "/"
+		path += options.IndexName
+	}
+
+	return []byte(path)
+}
+
+// Static serves the files of the root directory under the prefix according to
+// the options, which may be nil. GET and HEAD routes are added for the prefix,
+// which serves the index file, and for every path below it. Directories below
+// the prefix are redirected to their path with a trailing slash before their
+// index file is served. Paths with ".."
+// segments or encoded slashes are rejected with 400 Bad Request.
+func (r *Router) Static(prefix, root string, options *Static) {
+	r.static(prefix, root, options, nil)
+}
+
+// StaticFS serves the files of the file system under the prefix according to
+// the options, which may be nil, so that files embedded with go:embed can be
+// served. Static.Root is ignored. Use fs.Sub to serve a subdirectory of an
+// embed.FS.
+func (r *Router) StaticFS(prefix string, fsys fs.FS, options *Static) {
+	r.staticFS(prefix, http.FS(fsys), options, nil)
+}
+
+func (r *Router) static(prefix, root string, options *Static, group *Group) {
+	if options == nil {
+		options = &Static{}
+	}
+	if options.Root == "" {
+		options.Root = root
+	}
+	r.staticFS(prefix, http.Dir(options.Root), options, group)
+}
+
+func (r *Router) staticFS(prefix string, fsys http.FileSystem, options *Static, group *Group) {
+	if options == nil {
+		options = &Static{}
+	}
+
+	handler := &staticHandler{
+		fs:      fsys,
+		options: options,
+	}
+
+	// The prefix serves the index and the catch-all the files below it.
+	wildcard := strings.TrimSuffix(prefix, "/") + "/" + constants.WildcardSign
+	for _, path := range []string{prefix, wildcard} {
+		r.add(http.MethodGet, path, group, []Handler{handler.handle})
+		r.add(http.MethodHead, path, group, []Handler{handler.handle})
+	}
+}
+
+// staticHandler serves the files of a file system under a route prefix, the
+// path of the file being matched by the catch-all param of the route.
+type staticHandler struct {
+	fs      http.FileSystem
+	options *Static
+}
+
+func (h *staticHandler) handle(ctx *Context) error {
+	name := ctx.Param(constants.WildcardSign)
+	if !validStaticPath(ctx.Request, name) {
+		return NewHTTPError(http.StatusBadRequest)
+	}
+
+	name = path.Clean("/" + name)
+	file, err := h.open(name)
+	fallback := false
+	if errors.Is(err, fs.ErrNotExist) && h.options.SPA && path.Ext(name) == "" {
+		file, err = h.open("/")
+		fallback = true
+	}
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			h.options.notFoundHandler(ctx.ResponseWriter)
+			return nil
+		}
+		if errors.Is(err, fs.ErrPermission) {
+			return NewHTTPError(http.StatusForbidden).WithInternal(err)
+		}
+		return NewHTTPError(http.StatusInternalServerError).WithInternal(err)
+	}
+	defer file.Close()
+
+	// Like http.FileServer, directories are served from their slash-terminated
+	// path so that the relative links of their index resolve below them.
+	if urlPath := ctx.Request.URL.Path; !fallback && name != "/" && file.name != name && !strings.HasSuffix(urlPath, "/") {
+		target := path.Base(urlPath) + "/"
+		if query := ctx.Request.URL.RawQuery; query != "" {
+			target += "?" + query
+		}
+		http.Redirect(ctx.ResponseWriter, ctx.Request, target, http.StatusMovedPermanently)
+		return nil
+	}
+
+	h.serve(ctx, file, fallback)
+	return nil
+}
+
+// staticFile is a file opened by staticHandler, with the name it was opened
+// with.
+type staticFile struct {
+	http.File
+	name string
+	info fs.FileInfo
+}
+
+// open opens the file with the name, or the index file of the directory with
+// the name. Directories without an index file are not listed.
+func (h *staticHandler) open(name string) (*staticFile, error) {
+	file, err := h.openFile(name)
+	if err != nil || !file.info.IsDir() {
+		return file, err
+	}
+	file.Close()
+
+	indexName := h.options.IndexName
+	if indexName == "" {
+		indexName = DefaultIndexName
+	}
+	file, err = h.openFile(path.Join(name, indexName))
+	if err != nil {
+		return nil, err
+	}
+	if file.info.IsDir() {
+		file.Close()
+		return nil, fs.ErrNotExist
+	}
+	return file, nil
+}
+
+func (h *staticHandler) openFile(name string) (*staticFile, error) {
+	file, err := h.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &staticFile{File: file, name: name, info: info}, nil
+}
+
+// precompressedEncodings are the content codings of the precompressed
+// siblings of the files, such as app.js.br for app.js, by order of
+// preference.
+var precompressedEncodings = [...]struct {
+	coding string
+	ext    string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// openPrecompressed opens the precompressed sibling of the file preferred by
+// the request, and returns it with its content coding. It returns nil when
+// the request accepts none of the siblings of the file. The boolean reports
+// whether the file has any sibling, in which case the response depends on the
+// Accept-Encoding header of the request.
+func (h *staticHandler) openPrecompressed(r *http.Request, file *staticFile) (*staticFile, string, bool) {
+	found := false
+	for _, encoding := range precompressedEncodings {
+		sibling, err := h.openFile(file.name + encoding.ext)
+		if err != nil {
+			continue
+		}
+		if sibling.info.IsDir() {
+			sibling.Close()
+			continue
+		}
+		found = true
+		if !acceptsEncoding(r, encoding.coding) {
+			sibling.Close()
+			continue
+		}
+		return sibling, encoding.coding, true
+	}
+	return nil, "", found
+}
+
+// serve writes the file with http.ServeContent, which handles the
+// conditional and range requests, after applying the cache, range and
+// compression options. The SPA index served in place of a missing file must
+// be revalidated by clients instead of being cached.
+//
+// A precompressed sibling of the file accepted by the client is sent in place
+// of the file, with the content type of the file. Otherwise the file is
+// compressed on the fly when Static.Compress is set.
+func (h *staticHandler) serve(ctx *Context, file *staticFile, fallback bool) {
+	req := ctx.Request
+	w := &staticWriter{ResponseWriter: ctx.ResponseWriter, ranges: h.options.ByteRange}
+	header := w.Header()
+
+	if fallback {
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Expires", "0")
+	} else if h.options.CacheDuration > 0 {
+		header.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.options.CacheDuration.Seconds())))
+		header.Set("Expires", time.Now().Add(h.options.CacheDuration).UTC().Format(http.TimeFormat))
+	}
+
+	var content io.ReadSeeker = file
+	modTime := file.info.ModTime()
+	sibling, coding, vary := h.openPrecompressed(req, file)
+	if sibling != nil {
+		defer sibling.Close()
+		header.Set("Content-Type", contentType(file))
+		header.Set("Content-Encoding", coding)
+		header.Set("Content-Length", strconv.FormatInt(sibling.info.Size(), 10))
+		content = sibling
+		modTime = sibling.info.ModTime()
+	} else if h.options.Compress && compressible(file.name) {
+		vary = true
+		if file.info.Size() > 0 && acceptsEncoding(req, "gzip") {
+			header.Set("Content-Encoding", "gzip")
+			w.compress = true
+		}
+	}
+	if vary {
+		header.Add("Vary", "Accept-Encoding")
+	}
+
+	// Ranges of the file cannot be served from its compressed content.
+	if req.Header.Get("Range") != "" && (!w.ranges || header.Get("Content-Encoding") != "") {
+		req = req.Clone(req.Context())
+		req.Header.Del("Range")
+		w.ranges = false
+	}
+
+	http.ServeContent(w, req, file.info.Name(), modTime, content)
+	w.Close()
+}
+
+// contentType returns the content type of the file from its extension, or
+// from its first bytes when the extension is unknown.
+func contentType(file *staticFile) string {
+	if ctype := mime.TypeByExtension(path.Ext(file.name)); ctype != "" {
+		return ctype
+	}
+	var buf [512]byte
+	n, _ := io.ReadFull(file, buf[:])
+	return http.DetectContentType(buf[:n])
+}
+
+// staticWriter adjusts the responses of http.ServeContent to the Static
+// options. It hides the Accept-Ranges header when ranges are disabled and
+// gzips successful responses when compression was negotiated.
+type staticWriter struct {
+	http.ResponseWriter
+	ranges      bool
+	compress    bool
+	wroteHeader bool
+	gzip        *gzip.Writer
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+func (w *staticWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if !w.ranges {
+		w.Header().Del("Accept-Ranges")
+	}
+	// http.ServeContent drops Content-Encoding from error responses.
+	if code != http.StatusOK || w.Header().Get("Content-Encoding") != "gzip" {
+		w.compress = false
+	}
+	if w.compress {
+		w.Header().Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *staticWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.compress {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.gzip == nil {
+		w.gzip = gzipWriterPool.Get().(*gzip.Writer)
+		w.gzip.Reset(w.ResponseWriter)
+	}
+	return w.gzip.Write(b)
+}
+
+// Close flushes the compressed content, if any.
+func (w *staticWriter) Close() error {
+	if w.gzip == nil {
+		return nil
+	}
+	err := w.gzip.Close()
+	gzipWriterPool.Put(w.gzip)
+	w.gzip = nil
+	return err
+}
+
+// validStaticPath reports whether the name of the file requested is safe to
+// open. Names with ".." segments, backslashes or NUL bytes, and request paths
+// with encoded slashes, are rejected rather than resolved, so that requests
+// cannot reach files outside of the root.
+func validStaticPath(r *http.Request, name string) bool {
+	if strings.ContainsAny(name, "\\\x00") {
+		return false
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." {
+			return false
+		}
+	}
+	rawPath := strings.ToLower(r.URL.RawPath)
+	return !strings.Contains(rawPath, "%2f") && !strings.Contains(rawPath, "%5c")
+}
+
+// compressible reports whether the file with the name is worth compressing,
+// based on the content type of its extension.
+func compressible(name string) bool {
+	ctype, _, _ := strings.Cut(mime.TypeByExtension(path.Ext(name)), ";")
+	switch {
+	case strings.HasPrefix(ctype, "text/"),
+		strings.HasSuffix(ctype, "+json"),
+		strings.HasSuffix(ctype, "+xml"):
+		return true
+	}
+	switch ctype {
+	case "application/javascript", "application/json", "application/xml", "application/wasm":
+		return true
+	}
+	return false
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header of the request
+// accepts the content coding.
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(part, ";")
+			coding = strings.TrimSpace(coding)
+			if coding != "*" && !strings.EqualFold(coding, encoding) {
+				continue
+			}
+			if _, q, ok := strings.Cut(params, "q="); ok {
+				if weight, err := strconv.ParseFloat(strings.TrimSpace(q), 64); err == nil && weight == 0 {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,540 @@
+package pulse
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// writeFiles writes the files, keyed by their slash-separated path, to a
+// temporary directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// serveStatic makes a request to a router serving the root under /assets.
+func serveStatic(t *testing.T, root string, options *Static, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := NewRouter()
+	router.Static("/assets", root, options)
+
+	rec := httptest.NewRecorder()
+	RouterHandler(router).ServeHTTP(rec, req)
+	return rec
+}
+
+var indexHTML = "<!DOCTYPE html><html><body>" + strings.Repeat("pulse ", 100) + "</body></html>"
+
+func TestStatic_IndexName(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"index.html": "index",
+		"home.html":  "home",
+	})
+
+	rec := serveStatic(t, root, nil, httptest.NewRequest(http.MethodGet, "/assets", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "index" {
+		t.Errorf("expected the default index, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = serveStatic(t, root, &Static{IndexName: "home.html"}, httptest.NewRequest(http.MethodGet, "/assets", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "home" {
+		t.Errorf("expected the custom index, got %d %q", rec.Code, rec.Body.String())
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "text/html; charset=utf-8" {
+		t.Errorf("unexpected content type: %q", contentType)
+	}
+}
+
+func TestStatic_NotFound(t *testing.T) {
+	root := writeFiles(t, map[string]string{"app.js": "app"})
+
+	rec := serveStatic(t, root, &Static{CacheDuration: time.Hour}, httptest.NewRequest(http.MethodGet, "/assets", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unexpected status code: got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec.Body.String() != "404 Not Found" {
+		t.Errorf("unexpected body: %q", rec.Body.String())
+	}
+	if rec.Header().Get("Cache-Control") != "" {
+		t.Errorf("expected a missing file not to be cached")
+	}
+}
+
+func TestStatic_CacheDuration(t *testing.T) {
+	root := writeFiles(t, map[string]string{"index.html": "index"})
+
+	rec := serveStatic(t, root, &Static{CacheDuration: 24 * time.Hour}, httptest.NewRequest(http.MethodGet, "/assets", nil))
+	if cacheControl := rec.Header().Get("Cache-Control"); cacheControl != "public, max-age=86400" {
+		t.Errorf("unexpected Cache-Control: %q", cacheControl)
+	}
+	expires, err := http.ParseTime(rec.Header().Get("Expires"))
+	if err != nil {
+		t.Fatalf("invalid Expires: %v", err)
+	}
+	if d := time.Until(expires); d < 23*time.Hour || d > 25*time.Hour {
+		t.Errorf("expected Expires in a day, got %v", expires)
+	}
+
+	rec = serveStatic(t, root, nil, httptest.NewRequest(http.MethodGet, "/assets", nil))
+	if rec.Header().Get("Cache-Control") != "" || rec.Header().Get("Expires") != "" {
+		t.Errorf("expected no cache headers without CacheDuration")
+	}
+}
+
+func TestStatic_Compress(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"index.html": indexHTML,
+		"logo.png":   "\x89PNG\r\n\x1a\n" + strings.Repeat("x", 100),
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/assets", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	rec := serveStatic(t, root, &Static{Compress: true}, req)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a gzipped response, got headers %v", rec.Header())
+	}
+	if rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("unexpected Vary: %q", rec.Header().Get("Vary"))
+	}
+	if rec.Header().Get("Content-Length") != "" {
+		t.Errorf("expected no Content-Length for a gzipped response")
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != "text/html; charset=utf-8" {
+		t.Errorf("unexpected content type: %q", contentType)
+	}
+	reader, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != indexHTML {
+		t.Errorf("unexpected decompressed body: %q", body)
+	}
+
+	// Clients not accepting gzip get the raw file.
+	req = httptest.NewRequest(http.MethodGet, "/assets", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0, br")
+	rec = serveStatic(t, root, &Static{Compress: true}, req)
+	if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != indexHTML {
+		t.Errorf("expected the raw file, got headers %v", rec.Header())
+	}
+	if rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("expected Vary for a compressible file, got %q", rec.Header().Get("Vary"))
+	}
+
+	// Images are already compressed.
+	req = httptest.NewRequest(http.MethodGet, "/assets", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec = serveStatic(t, root, &Static{Compress: true, IndexName: "logo.png"}, req)
+	if rec.Header().Get("Content-Encoding") != "" || rec.Header().Get("Vary") != "" {
+		t.Errorf("expected an image not to be compressed, got headers %v", rec.Header())
+	}
+
+	// HEAD requests have no body to compress.
+	req = httptest.NewRequest(http.MethodHead, "/assets", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec = serveStatic(t, root, &Static{Compress: true}, req)
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected no body for a HEAD request, got %d bytes", rec.Body.Len())
+	}
+}
+
+func TestStatic_ByteRange(t *testing.T) {
+	root := writeFiles(t, map[string]string{"index.html": indexHTML})
+
+	req := httptest.NewRequest(http.MethodGet, "/assets", nil)
+	req.Header.Set("Range", "bytes=0-14")
+	rec := serveStatic(t, root, &Static{ByteRange: true}, req)
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != indexHTML[:15] {
+		t.Errorf("expected a partial response, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Accept-Ranges") != "bytes" {
+		t.Errorf("expected Accept-Ranges to be advertised")
+	}
+
+	// If-Range only serves the range when the file is unchanged.
+	req = httptest.NewRequest(http.MethodGet, "/assets", nil)
+	req.Header.Set("Range", "bytes=0-14")
+	req.Header.Set("If-Range", time.Unix(0, 0).UTC().Format(http.TimeFormat))
+	rec = serveStatic(t, root, &Static{ByteRange: true}, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != indexHTML {
+		t.Errorf("expected the whole file for a stale If-Range, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/assets", nil)
+	req.Header.Set("Range", "bytes=0-14")
+	rec = serveStatic(t, root, &Static{}, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != indexHTML {
+		t.Errorf("expected the whole file when ranges are disabled, got %d", rec.Code)
+	}
+	if rec.Header().Get("Accept-Ranges") != "" {
+		t.Errorf("expected Accept-Ranges not to be advertised")
+	}
+
+	// Ranges are not served from compressed content.
+	req = httptest.NewRequest(http.MethodGet, "/assets", nil)
+	req.Header.Set("Range", "bytes=0-14")
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec = serveStatic(t, root, &Static{ByteRange: true, Compress: true}, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("expected a whole gzipped response, got %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"br, gzip;q=0.5", true},
+		{"GZIP", true},
+		{"gzip;q=0", false},
+		{"br", false},
+		{"*", true},
+		{"deflate, *;q=0", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", tt.header)
+		if got := acceptsEncoding(req, "gzip"); got != tt.want {
+			t.Errorf("acceptsEncoding(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestRouter_StaticFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":     {Data: []byte(indexHTML), ModTime: time.Now()},
+		"docs/main.html": {Data: []byte("docs")},
+	}
+
+	router := NewRouter()
+	router.StaticFS("/assets", fsys, &Static{Compress: true, CacheDuration: time.Minute})
+	router.StaticFS("/docs", fsys, &Static{IndexName: "docs/main.html"})
+	router.StaticFS("/missing", fsys, &Static{IndexName: "missing.html"})
+	handler := RouterHandler(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/assets", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("expected a gzipped index, got %d %v", rec.Code, rec.Header())
+	}
+	if rec.Header().Get("Cache-Control") != "public, max-age=60" {
+		t.Errorf("unexpected Cache-Control: %q", rec.Header().Get("Cache-Control"))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "docs" {
+		t.Errorf("expected the custom index, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rec.Code != http.StatusNotFound || rec.Body.String() != "404 Not Found" {
+		t.Errorf("expected a 404, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGroup_StaticFS(t *testing.T) {
+	fsys := fstest.MapFS{"index.html": {Data: []byte("index")}}
+
+	router := NewRouter()
+	api := &Group{Prefix: "/api", Router: router}
+	api.Use(MiddlewareFunc(func(handler Handler) Handler {
+		return func(ctx *Context) error {
+			ctx.SetResponseHeader("X-Group", "api")
+			return handler(ctx)
+		}
+	}))
+	api.StaticFS("/assets", fsys, nil)
+
+	rec := httptest.NewRecorder()
+	RouterHandler(router).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/assets", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "index" {
+		t.Errorf("expected the index, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("X-Group") != "api" {
+		t.Errorf("expected the group middleware to run")
+	}
+}
+
+func TestStatic_Nested(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"index.html":           "index",
+		"css/app.css":          "body {}",
+		"js/vendor/lib.js":     "lib",
+		"docs/index.html":      "docs",
+		"empty/.gitkeep":       "",
+		"names/with space.txt": "space",
+	})
+
+	tests := []struct {
+		method string
+		target string
+		code   int
+		body   string
+	}{
+		{http.MethodGet, "/assets", http.StatusOK, "index"},
+		{http.MethodGet, "/assets/", http.StatusOK, "index"},
+		{http.MethodGet, "/assets/css/app.css", http.StatusOK, "body {}"},
+		{http.MethodGet, "/assets/js/vendor/lib.js", http.StatusOK, "lib"},
+		{http.MethodGet, "/assets/docs/", http.StatusOK, "docs"},
+		{http.MethodGet, "/assets/names/with%20space.txt", http.StatusOK, "space"},
+		{http.MethodGet, "/assets/empty/", http.StatusNotFound, "404 Not Found"},
+		{http.MethodGet, "/assets/css/missing.css", http.StatusNotFound, "404 Not Found"},
+		{http.MethodHead, "/assets/css/app.css", http.StatusOK, ""},
+		{http.MethodPost, "/assets/css/app.css", http.StatusMethodNotAllowed, "Method Not Allowed"},
+	}
+	for _, tt := range tests {
+		rec := serveStatic(t, root, nil, httptest.NewRequest(tt.method, tt.target, nil))
+		if rec.Code != tt.code || strings.TrimSpace(rec.Body.String()) != tt.body {
+			t.Errorf("%s %s: expected %d %q, got %d %q", tt.method, tt.target, tt.code, tt.body, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := serveStatic(t, root, nil, httptest.NewRequest(http.MethodHead, "/assets/css/app.css", nil))
+	if rec.Header().Get("Content-Length") != "7" || rec.Header().Get("Content-Type") != "text/css; charset=utf-8" {
+		t.Errorf("expected the headers of the file for HEAD, got %v", rec.Header())
+	}
+
+	// Directories are redirected to their slash-terminated path, so that the
+	// relative links of their index resolve below them.
+	for target, location := range map[string]string{
+		"/assets/docs":         "/assets/docs/",
+		"/assets/docs?lang=en": "/assets/docs/?lang=en",
+	} {
+		rec = serveStatic(t, root, nil, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != location {
+			t.Errorf("%s: expected a redirect to %q, got %d %q", target, location, rec.Code, rec.Header().Get("Location"))
+		}
+	}
+}
+
+func TestStatic_PathTraversal(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"secret.txt":         "secret",
+		"public/index.html":  "index",
+		"public/css/app.css": "body {}",
+	})
+	root := filepath.Join(dir, "public")
+
+	targets := []string{
+		"/assets/../secret.txt",
+		"/assets/css/../../secret.txt",
+		"/assets/%2e%2e/secret.txt",
+		"/assets/..%2Fsecret.txt",
+		"/assets/css%2Fapp.css",
+		"/assets/css%2fapp.css",
+		"/assets/..%5Csecret.txt",
+		"/assets/css%5Capp.css",
+		"/assets/%00",
+	}
+	for _, target := range targets {
+		rec := serveStatic(t, root, nil, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected %d, got %d %q", target, http.StatusBadRequest, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestStatic_SPA(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"index.html":    "index",
+		"app.js":        "app",
+		"css/style.css": "style",
+	})
+
+	router := NewRouter()
+	router.Get("/api/users", func(ctx *Context) error {
+		ctx.String("users")
+		return nil
+	})
+	router.Static("/", root, &Static{SPA: true, CacheDuration: time.Hour})
+	handler := RouterHandler(router)
+
+	tests := []struct {
+		target       string
+		code         int
+		body         string
+		cacheControl string
+	}{
+		{"/", http.StatusOK, "index", "public, max-age=3600"},
+		{"/app.js", http.StatusOK, "app", "public, max-age=3600"},
+		{"/css/style.css", http.StatusOK, "style", "public, max-age=3600"},
+		{"/api/users", http.StatusOK, "users", ""},
+		{"/api/users/", http.StatusOK, "users", ""},
+		{"/dashboard", http.StatusOK, "index", "no-cache"},
+		{"/users/42/settings", http.StatusOK, "index", "no-cache"},
+		{"/css/", http.StatusOK, "index", "no-cache"},
+		{"/missing.js", http.StatusNotFound, "404 Not Found", ""},
+		{"/css/missing.css", http.StatusNotFound, "404 Not Found", ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rec.Code != tt.code || rec.Body.String() != tt.body {
+			t.Errorf("%s: expected %d %q, got %d %q", tt.target, tt.code, tt.body, rec.Code, rec.Body.String())
+		}
+		if cacheControl := rec.Header().Get("Cache-Control"); cacheControl != tt.cacheControl {
+			t.Errorf("%s: expected Cache-Control %q, got %q", tt.target, tt.cacheControl, cacheControl)
+		}
+	}
+
+	// Without SPA, client-side routes are missing files.
+	rec := serveStatic(t, root, nil, httptest.NewRequest(http.MethodGet, "/assets/dashboard", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected a 404 without SPA, got %d", rec.Code)
+	}
+
+	// The index of the root is served under a prefix too.
+	rec = serveStatic(t, root, &Static{SPA: true}, httptest.NewRequest(http.MethodGet, "/assets/dashboard", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "index" {
+		t.Errorf("expected the index under the prefix, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	// A SPA without an index has nothing to fall back to.
+	empty := writeFiles(t, map[string]string{"app.js": "app"})
+	rec = serveStatic(t, empty, &Static{SPA: true}, httptest.NewRequest(http.MethodGet, "/assets/dashboard", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected a 404 without an index, got %d", rec.Code)
+	}
+}
+
+func TestStatic_Precompressed(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js":           {Data: []byte("console.log('pulse')")},
+		"app.js.br":        {Data: []byte("brotli app")},
+		"app.js.gz":        {Data: []byte("gzip app")},
+		"css/style.css":    {Data: []byte(indexHTML)},
+		"css/style.css.gz": {Data: []byte("gzip style")},
+		"index.html":       {Data: []byte(indexHTML)},
+		"index.html.gz":    {Data: []byte("gzip index")},
+		"data":             {Data: []byte("%PDF-1.4 data")},
+		"data.br":          {Data: []byte("brotli data")},
+		"page.html":        {Data: []byte(indexHTML)},
+	}
+
+	router := NewRouter()
+	router.StaticFS("/assets", fsys, &Static{Compress: true, ByteRange: true})
+	handler := RouterHandler(router)
+
+	tests := []struct {
+		method         string
+		target         string
+		acceptEncoding string
+		encoding       string
+		contentType    string
+		body           string
+	}{
+		{http.MethodGet, "/assets/app.js", "gzip, deflate, br", "br", "text/javascript; charset=utf-8", "brotli app"},
+		{http.MethodGet, "/assets/app.js", "gzip", "gzip", "text/javascript; charset=utf-8", "gzip app"},
+		{http.MethodGet, "/assets/app.js", "br;q=0, gzip", "gzip", "text/javascript; charset=utf-8", "gzip app"},
+		{http.MethodGet, "/assets/app.js", "", "", "text/javascript; charset=utf-8", "console.log('pulse')"},
+		{http.MethodGet, "/assets/css/style.css", "br, gzip", "gzip", "text/css; charset=utf-8", "gzip style"},
+		{http.MethodGet, "/assets/css/style.css", "br", "", "text/css; charset=utf-8", indexHTML},
+		{http.MethodGet, "/assets", "gzip", "gzip", "text/html; charset=utf-8", "gzip index"},
+		{http.MethodGet, "/assets/data", "br", "br", "application/pdf", "brotli data"},
+		{http.MethodHead, "/assets/app.js", "br", "br", "text/javascript; charset=utf-8", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, nil)
+		req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		name := tt.method + " " + tt.target + " (" + tt.acceptEncoding + ")"
+		if rec.Code != http.StatusOK || rec.Body.String() != tt.body {
+			t.Errorf("%s: expected %q, got %d %q", name, tt.body, rec.Code, rec.Body.String())
+		}
+		if encoding := rec.Header().Get("Content-Encoding"); encoding != tt.encoding {
+			t.Errorf("%s: expected Content-Encoding %q, got %q", name, tt.encoding, encoding)
+		}
+		if contentType := rec.Header().Get("Content-Type"); contentType != tt.contentType {
+			t.Errorf("%s: expected Content-Type %q, got %q", name, tt.contentType, contentType)
+		}
+		if tt.encoding != "" {
+			if rec.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("%s: expected Vary, got %q", name, rec.Header().Get("Vary"))
+			}
+			if tt.method == http.MethodGet && rec.Header().Get("Content-Length") != strconv.Itoa(len(tt.body)) {
+				t.Errorf("%s: expected the Content-Length of the sibling, got %q", name, rec.Header().Get("Content-Length"))
+			}
+		}
+	}
+
+	// Files without a sibling are compressed on the fly.
+	req := httptest.NewRequest(http.MethodGet, "/assets/page.html", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected an on-the-fly gzipped response, got %v", rec.Header())
+	}
+	reader, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(reader); string(body) != indexHTML {
+		t.Errorf("unexpected decompressed body: %q", body)
+	}
+
+	// Ranges are not served from precompressed content.
+	req = httptest.NewRequest(http.MethodGet, "/assets/app.js", nil)
+	req.Header.Set("Accept-Encoding", "br")
+	req.Header.Set("Range", "bytes=0-3")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "brotli app" {
+		t.Errorf("expected the whole sibling, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	// Responses vary by Accept-Encoding whenever a sibling exists, even when
+	// the file itself is sent.
+	router = NewRouter()
+	router.StaticFS("/assets", fsys, nil)
+	handler = RouterHandler(router)
+	for target, vary := range map[string]string{
+		"/assets/app.js":    "Accept-Encoding",
+		"/assets/data":      "Accept-Encoding",
+		"/assets/page.html": "",
+	} {
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Header().Get("Content-Encoding") != "" {
+			t.Errorf("%s: expected an identity response, got %q", target, rec.Header().Get("Content-Encoding"))
+		}
+		if got := rec.Header().Values("Vary"); len(got) > 1 || rec.Header().Get("Vary") != vary {
+			t.Errorf("%s: expected Vary %q, got %q", target, vary, got)
+		}
+	}
+}
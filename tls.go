@@ -0,0 +1,197 @@
+package pulse
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultCertReloadInterval is how often RunTLS checks the certificate files
+// for changes.
+const DefaultCertReloadInterval = 10 * time.Second
+
+// RunTLS serves HTTPS on the address with the certificate and key files. The
+// files are checked for changes at most every DefaultCertReloadInterval and
+// reloaded when they change, so that renewed certificates are picked up
+// without a restart.
+func (p *Pulse) RunTLS(address, certFile, keyFile string) error {
+	reloader, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+
+	return p.RunTLSWithConfig(address, &tls.Config{
+		GetCertificate: reloader.GetCertificate,
+	})
+}
+
+// RunTLSWithConfig serves HTTPS on the address with the TLS config, which
+// must provide the certificates through Certificates or GetCertificate, for
+// instance to serve several domains with SNI. When Config.HTTPRedirectAddress
+// is set, plain HTTP requests to that address are redirected to HTTPS. Like
+// Run, the server is stopped gracefully on SIGINT or SIGTERM when
+// Config.GracefulShutdown is set.
+func (p *Pulse) RunTLSWithConfig(address string, config *tls.Config) error {
+	p.server.Handler = p.Handler()
+	p.server.TLSConfig = config
+
+	listener, err := p.listen(address)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := p.signalContext()
+	defer stop()
+
+	// A failure of the redirect server stops the HTTPS server too.
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var redirectListener net.Listener
+	if p.config.HTTPRedirectAddress != "" {
+		redirectListener, err = net.Listen(p.config.Network, p.config.HTTPRedirectAddress)
+		if err != nil {
+			listener.Close()
+			return fmt.Errorf("failed to listen for HTTP redirects: %w", err)
+		}
+
+		_, port, _ := net.SplitHostPort(listener.Addr().String())
+		redirectServer := p.newServer()
+		redirectServer.Handler = redirectHTTPS(port)
+		p.mu.Lock()
+		p.redirectServer = redirectServer
+		p.mu.Unlock()
+		go func() {
+			err := redirectServer.Serve(redirectListener)
+			if !errors.Is(err, http.ErrServerClosed) {
+				cancel(fmt.Errorf("failed to serve HTTP redirects on %s: %w", redirectListener.Addr(), err))
+			}
+		}()
+	}
+
+	fmt.Println(p.startupMessage(listener.Addr().String()))
+
+	server := p.server
+	err = p.serve(ctx, listener, func(l net.Listener) error {
+		return server.ServeTLS(l, "", "")
+	})
+	if cause := context.Cause(ctx); cause != nil && !errors.Is(cause, context.Canceled) {
+		err = errors.Join(cause, err)
+	}
+	if err != nil {
+		p.closeRedirectServer()
+		// The redirect server may not track its listener yet.
+		if redirectListener != nil {
+			redirectListener.Close()
+		}
+	}
+	return err
+}
+
+// closeRedirectServer closes the redirect server, if any, without waiting for
+// its requests to finish.
+func (p *Pulse) closeRedirectServer() {
+	p.mu.Lock()
+	redirectServer := p.redirectServer
+	p.redirectServer = nil
+	p.mu.Unlock()
+	if redirectServer != nil {
+		redirectServer.Close()
+	}
+}
+
+// redirectHTTPS returns a handler redirecting requests to the same URL over
+// HTTPS on the given port. 308 Permanent Redirect is used so that clients do
+// not turn redirected POST or PUT requests into GET requests.
+func redirectHTTPS(port string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// certReloader serves a certificate loaded from files and reloads it when the
+// files change on disk.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: DefaultCertReloadInterval,
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, reloading it first if the
+// files changed since it was loaded. The previous certificate is kept when
+// the new files cannot be loaded, such as while they are being written.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checked) >= r.interval {
+		_ = r.reload()
+	}
+	return r.cert, nil
+}
+
+// reload loads the certificate if the files were modified since the last
+// load. It must be called with mu held, or before r is shared.
+func (r *certReloader) reload() error {
+	r.checked = time.Now()
+
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+	if r.cert != nil && !modTime.After(r.modTime) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to load certificate: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,261 @@
+package pulse
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeCertificate writes a self-signed certificate for 127.0.0.1 with the
+// given serial number to dir and returns the paths of the certificate and key.
+func writeCertificate(t *testing.T, dir string, serial int64) (string, string, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "pulse test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile, cert
+}
+
+// waitForServer waits until a connection to the address succeeds.
+func waitForServer(t *testing.T, address string) {
+	t.Helper()
+
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if conn, err := net.Dial("tcp", address); err == nil {
+			conn.Close()
+			return
+		}
+	}
+	t.Fatalf("server on %s did not start", address)
+}
+
+func TestPulse_RunTLS(t *testing.T) {
+	certFile, keyFile, cert := writeCertificate(t, t.TempDir(), 1)
+
+	app := New(Config{HTTPRedirectAddress: "127.0.0.1:9080"})
+	app.Router.Get("/hello", func(ctx *Context) error {
+		ctx.String("hello over " + ctx.Request.Proto)
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.RunTLS("127.0.0.1:9443", certFile, keyFile)
+	}()
+	waitForServer(t, "127.0.0.1:9443")
+	waitForServer(t, "127.0.0.1:9080")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Get("https://127.0.0.1:9443/hello")
+	if err != nil {
+		t.Fatalf("failed to make HTTPS request: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "hello over HTTP/1.1" {
+		t.Errorf("unexpected body: %q", body)
+	}
+
+	res, err = client.Get("http://127.0.0.1:9080/hello?name=pulse")
+	if err != nil {
+		t.Fatalf("failed to make HTTP request: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusPermanentRedirect {
+		t.Errorf("unexpected status code: got %d, want %d", res.StatusCode, http.StatusPermanentRedirect)
+	}
+	if location := res.Header.Get("Location"); location != "https://127.0.0.1:9443/hello?name=pulse" {
+		t.Errorf("unexpected redirect location: %q", location)
+	}
+
+	// Clients keep the method of redirected requests.
+	res, err = client.Post("http://127.0.0.1:9080/hello", "text/plain", strings.NewReader("pulse"))
+	if err != nil {
+		t.Fatalf("failed to make HTTP request: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusPermanentRedirect {
+		t.Errorf("unexpected status code for POST: got %d, want %d", res.StatusCode, http.StatusPermanentRedirect)
+	}
+
+	// Unused connections would keep the server from shutting down in time.
+	client.CloseIdleConnections()
+	if err := app.Stop(); err != nil {
+		t.Errorf("failed to stop server: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("expected RunTLS to return nil once stopped, got %v", err)
+	}
+}
+
+func TestPulse_RunTLSWithConfig(t *testing.T) {
+	certFile, keyFile, cert := writeCertificate(t, t.TempDir(), 1)
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := New()
+	app.Router.Get("/", func(ctx *Context) error {
+		ctx.String("hello")
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.RunTLSWithConfig("127.0.0.1:9444", &tls.Config{Certificates: []tls.Certificate{pair}})
+	}()
+	waitForServer(t, "127.0.0.1:9444")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+
+	res, err := client.Get("https://127.0.0.1:9444/")
+	if err != nil {
+		t.Fatalf("failed to make HTTPS request: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	if err := app.Stop(); err != nil {
+		t.Errorf("failed to stop server: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("expected RunTLSWithConfig to return nil once stopped, got %v", err)
+	}
+}
+
+func TestPulse_RunTLSMissingFiles(t *testing.T) {
+	app := New()
+	if err := app.RunTLS("127.0.0.1:9445", "missing.pem", "missing-key.pem"); err == nil {
+		t.Errorf("expected an error for missing certificate files")
+	}
+}
+
+func TestPulse_RunTLSWithConfigFailure(t *testing.T) {
+	app := New(Config{HTTPRedirectAddress: "127.0.0.1:9081"})
+	if err := app.RunTLSWithConfig("127.0.0.1:9446", &tls.Config{}); err == nil {
+		t.Fatalf("expected an error without certificates")
+	}
+	if app.redirectServer != nil {
+		t.Errorf("expected the redirect server to be cleared")
+	}
+
+	// Both listeners are closed when the server fails to start.
+	for _, address := range []string{"127.0.0.1:9446", "127.0.0.1:9081"} {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			t.Fatalf("expected %s to be free: %v", address, err)
+		}
+		listener.Close()
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, _ := writeCertificate(t, dir, 1)
+
+	reloader, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloader.interval = 0
+
+	serial := func() int64 {
+		cert, err := reloader.GetCertificate(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.SerialNumber.Int64()
+	}
+
+	if s := serial(); s != 1 {
+		t.Errorf("expected serial 1, got %d", s)
+	}
+
+	writeCertificate(t, dir, 2)
+	later := time.Now().Add(time.Minute)
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := serial(); s != 2 {
+		t.Errorf("expected reloaded serial 2, got %d", s)
+	}
+
+	// A broken certificate keeps the previous one in use.
+	if err := os.WriteFile(certFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later = later.Add(time.Minute)
+	if err := os.Chtimes(certFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if s := serial(); s != 2 {
+		t.Errorf("expected serial 2 to be kept, got %d", s)
+	}
+}
@@ -0,0 +1,232 @@
+package pulse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gopulse/pulse/constants"
+)
+
+type nodeKind uint8
+
+const (
+	staticNode nodeKind = iota
+	paramNode
+	wildcardNode
+)
+
+// node is a node of the compressed radix tree used to match request paths.
+// Static children are looked up by the first byte of their prefix, param
+// children consume a single path segment and the wildcard child consumes the
+// rest of the path. Lookups try them in that order, so static segments always
+// win over params and params always win over wildcards.
+type node struct {
+	kind     nodeKind
+	prefix   string
+	indices  string
+	children []*node
+	params   []*node
+	wildcard *node
+
+	spec       string
+	constraint Constraint
+
+	route      *Route
+	paramNames []string
+}
+
+// insert adds the route to the tree under the given path. If a route is
+// already registered under the same path, the tree is left unchanged and the
+// existing route is returned. Param constraints are resolved with constraint.
+func (n *node) insert(path string, route *Route, constraint func(spec string) Constraint) *Route {
+	var names []string
+
+	for len(path) > 0 {
+		start := segmentStart(path)
+		if start < 0 {
+			n = n.addStatic(path)
+			break
+		}
+		if start > 0 {
+			n = n.addStatic(path[:start])
+			path = path[start:]
+		}
+
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		segment := path[:end]
+		path = path[end:]
+
+		if segment == constants.WildcardSign {
+			if path != "" {
+				panic(fmt.Sprintf("pulse: wildcard must be the last segment of route %q", route.Path))
+			}
+			if n.wildcard == nil {
+				n.wildcard = &node{kind: wildcardNode}
+			}
+			n = n.wildcard
+			names = append(names, constants.WildcardSign)
+			break
+		}
+
+		name, spec, _ := parseParam(segment)
+		n = n.addParam(spec, constraint)
+		names = append(names, name)
+	}
+
+	if n.route != nil {
+		return n.route
+	}
+	n.route = route
+	n.paramNames = names
+	return nil
+}
+
+// addStatic walks the static children of n along path, splitting nodes where
+// the prefixes diverge, and returns the node at the end of path.
+func (n *node) addStatic(path string) *node {
+	for len(path) > 0 {
+		i := strings.IndexByte(n.indices, path[0])
+		if i < 0 {
+			child := &node{kind: staticNode, prefix: path}
+			n.indices += path[:1]
+			n.children = append(n.children, child)
+			return child
+		}
+
+		child := n.children[i]
+		l := commonPrefix(path, child.prefix)
+		if l < len(child.prefix) {
+			rest := *child
+			rest.prefix = child.prefix[l:]
+			*child = node{
+				kind:     staticNode,
+				prefix:   child.prefix[:l],
+				indices:  rest.prefix[:1],
+				children: []*node{&rest},
+			}
+		}
+
+		path = path[l:]
+		n = child
+	}
+	return n
+}
+
+// addParam returns the param child of n with the given constraint spec,
+// creating it if needed. Constrained params are kept ahead of the
+// unconstrained one so they get a chance to match first.
+func (n *node) addParam(spec string, constraint func(spec string) Constraint) *node {
+	for _, child := range n.params {
+		if child.spec == spec {
+			return child
+		}
+	}
+
+	child := &node{kind: paramNode, spec: spec}
+	if spec == "" {
+		n.params = append(n.params, child)
+		return child
+	}
+
+	child.constraint = constraint(spec)
+	i := len(n.params)
+	if i > 0 && n.params[i-1].spec == "" {
+		i--
+	}
+	n.params = append(n.params, nil)
+	copy(n.params[i+1:], n.params[i:])
+	n.params[i] = child
+	return child
+}
+
+// search returns the node holding a route for path, which is the part of the
+// request path left after n, along with the values captured by params and
+// wildcards on the way. It backtracks when a more specific branch fails.
+func (n *node) search(path string, values []string) (*node, []string) {
+	return n.match(path, values, true)
+}
+
+// match is search, with wildcards only considered when wildcards is set.
+func (n *node) match(path string, values []string, wildcards bool) (*node, []string) {
+	if path == "" && n.route != nil {
+		return n, values
+	}
+
+	if path != "" {
+		if i := strings.IndexByte(n.indices, path[0]); i >= 0 {
+			child := n.children[i]
+			if strings.HasPrefix(path, child.prefix) {
+				if found, v := child.match(path[len(child.prefix):], values, wildcards); found != nil {
+					return found, v
+				}
+			}
+		}
+
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if end > 0 {
+			for _, child := range n.params {
+				if child.constraint != nil && !child.constraint(path[:end]) {
+					continue
+				}
+				if found, v := child.match(path[end:], append(values, path[:end]), wildcards); found != nil {
+					return found, v
+				}
+			}
+		}
+	}
+
+	if wildcards && n.wildcard != nil && n.wildcard.route != nil {
+		return n.wildcard, append(values, path)
+	}
+
+	return nil, values
+}
+
+// segmentStart returns the index of the first param or wildcard segment in
+// path, or -1 if the path is fully static.
+func segmentStart(path string) int {
+	for i := 0; i < len(path); i++ {
+		if i > 0 && path[i-1] != '/' {
+			continue
+		}
+		if path[i] == constants.ParamSign[0] {
+			return i
+		}
+		if path[i] == constants.WildcardSign[0] && (i+1 == len(path) || path[i+1] == '/') {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseParam splits a param segment such as ":id<int>?" into its name, its
+// constraint spec and whether it is optional.
+func parseParam(segment string) (name, spec string, optional bool) {
+	name = strings.TrimPrefix(segment, constants.ParamSign)
+	if strings.HasSuffix(name, constants.OptionalSign) {
+		name = strings.TrimSuffix(name, constants.OptionalSign)
+		optional = true
+	}
+
+	if i := strings.IndexByte(name, '<'); i >= 0 {
+		if name[len(name)-1] != '>' {
+			panic(fmt.Sprintf("pulse: unterminated constraint in param %q", segment))
+		}
+		name, spec = name[:i], name[i+1:len(name)-1]
+	}
+	return name, spec, optional
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,104 @@
+package pulse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNode_search(t *testing.T) {
+	tree := &node{}
+	paths := []string{
+		"/",
+		"/users",
+		"/users/new",
+		"/users/:id",
+		"/users/:id/posts",
+		"/users/:id/posts/:post",
+		"/files/*",
+		"/files/static/app.js",
+		"/search",
+		"/support",
+		"/:lang/docs",
+	}
+	for _, path := range paths {
+		tree.insert(path, &Route{Path: path}, nil)
+	}
+
+	tests := []struct {
+		path   string
+		route  string
+		values []string
+	}{
+		{"/", "/", nil},
+		{"/users", "/users", nil},
+		{"/users/new", "/users/new", nil},
+		{"/users/42", "/users/:id", []string{"42"}},
+		{"/users/42/posts", "/users/:id/posts", []string{"42"}},
+		{"/users/42/posts/7", "/users/:id/posts/:post", []string{"42", "7"}},
+		{"/files/", "/files/*", []string{""}},
+		{"/files/css/app.css", "/files/*", []string{"css/app.css"}},
+		{"/files/static/app.js", "/files/static/app.js", nil},
+		{"/files/static/app.css", "/files/*", []string{"static/app.css"}},
+		{"/search", "/search", nil},
+		{"/support", "/support", nil},
+		{"/en/docs", "/:lang/docs", []string{"en"}},
+		{"/users/docs", "/users/:id", []string{"docs"}},
+		{"/search/docs", "/:lang/docs", []string{"search"}},
+		{"/sup", "", nil},
+		{"/users/42/comments", "", nil},
+		{"/users//posts", "", nil},
+	}
+
+	for _, tt := range tests {
+		n, values := tree.search(tt.path, nil)
+		if tt.route == "" {
+			if n != nil {
+				t.Errorf("search(%q): expected no match, got %q", tt.path, n.route.Path)
+			}
+			continue
+		}
+		if n == nil {
+			t.Errorf("search(%q): expected %q, got no match", tt.path, tt.route)
+			continue
+		}
+		if n.route.Path != tt.route {
+			t.Errorf("search(%q): expected %q, got %q", tt.path, tt.route, n.route.Path)
+		}
+		if !reflect.DeepEqual(values, tt.values) {
+			t.Errorf("search(%q): expected values %v, got %v", tt.path, tt.values, values)
+		}
+	}
+}
+
+func TestNode_paramNames(t *testing.T) {
+	tree := &node{}
+	tree.insert("/users/:id/posts/:post", &Route{}, nil)
+	tree.insert("/users/:name", &Route{}, nil)
+	tree.insert("/users/:name/*", &Route{}, nil)
+
+	n, _ := tree.search("/users/1/posts/2", nil)
+	if !reflect.DeepEqual(n.paramNames, []string{"id", "post"}) {
+		t.Errorf("unexpected param names: %v", n.paramNames)
+	}
+
+	n, _ = tree.search("/users/john", nil)
+	if !reflect.DeepEqual(n.paramNames, []string{"name"}) {
+		t.Errorf("unexpected param names: %v", n.paramNames)
+	}
+
+	n, _ = tree.search("/users/john/a/b", nil)
+	if !reflect.DeepEqual(n.paramNames, []string{"name", "*"}) {
+		t.Errorf("unexpected param names: %v", n.paramNames)
+	}
+}
+
+func TestNode_insertWildcardNotLast(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected insert to panic")
+		}
+	}()
+
+	tree := &node{}
+	tree.insert("/files/*/edit", &Route{Path: "/files/*/edit"}, nil)
+}